@@ -0,0 +1,139 @@
+package dataset
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func Test_Ints(t *testing.T) {
+	for _, d := range Dists {
+		a := New(7).Ints(d, 1000)
+		b := New(7).Ints(d, 1000)
+		if !slices.Equal(a, b) {
+			t.Errorf("%v: the same seed generated different data", d)
+		}
+		if slices.Equal(a, New(8).Ints(d, 1000)) {
+			t.Errorf("%v: different seeds generated the same data", d)
+		}
+		for _, v := range a {
+			if v < 0 || v >= 1000 {
+				t.Fatalf("%v: value %d out of [0, 1000)", d, v)
+			}
+		}
+	}
+
+	if s := New(1).Ints(Sorted, 100); !slices.IsSorted(s) {
+		t.Errorf("sorted: got unsorted data")
+	}
+	if s := New(1).Ints(ReverseSorted, 100); !slices.IsSortedFunc(s, func(a, b int) int { return b - a }) {
+		t.Errorf("reverse-sorted: expected descending data")
+	}
+	if s := New(1).Ints(FewUnique, 1000); len(slices.Compact(slices.Sorted(slices.Values(s)))) > FewUniqueValues {
+		t.Errorf("few-unique: too many distinct values")
+	}
+	if s := New(1).Ints(Zipf, 1000); slices.Index(s, 0) < 0 {
+		t.Errorf("zipf: expected the smallest value to be common")
+	}
+	if s := New(1).Ints(Uniform, 0); len(s) != 0 {
+		t.Errorf("expected an empty slice")
+	}
+}
+
+// The PCG output for a seed is fixed, so the data must not change between
+// Go releases or machines.
+func Test_Ints_Golden(t *testing.T) {
+	got := New(42).Ints(Uniform, 8)
+	exp := []int{0, 0, 1, 6, 2, 2, 2, 5}
+	if !slices.Equal(got, exp) {
+		t.Errorf("expected %v but got %v", exp, got)
+	}
+}
+
+func Test_ParseDist(t *testing.T) {
+	for _, d := range Dists {
+		got, err := ParseDist(d.String())
+		if err != nil || got != d {
+			t.Errorf("ParseDist(%q) = %v, %v", d.String(), got, err)
+		}
+	}
+	if _, err := ParseDist("gaussian"); err == nil {
+		t.Errorf("expected an error for an unknown distribution")
+	}
+}
+
+func Test_Strings(t *testing.T) {
+	s := New(3).Strings(10, 20, "ab")
+	if len(s) != 10 {
+		t.Fatalf("expected 10 strings but got %d", len(s))
+	}
+	for _, v := range s {
+		if len(v) != 20 || strings.Trim(v, "ab") != "" {
+			t.Errorf("unexpected string %q", v)
+		}
+	}
+	if got := New(3).String(5, "äö"); len([]rune(got)) != 5 {
+		t.Errorf("expected 5 runes but got %q", got)
+	}
+}
+
+func Test_SaveLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	ints := New(1).Ints(Uniform, 10000)
+	ints = append(ints, -1, 1<<62, -1<<62)
+	path := filepath.Join(dir, "ints.dset")
+	if err := SaveInts(path, ints); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadInts(path)
+	if err != nil || !slices.Equal(got, ints) {
+		t.Errorf("ints did not round trip: %v", err)
+	}
+
+	strs := append(New(1).Strings(100, 10, Letters), "", "ünïcode")
+	path = filepath.Join(dir, "strings.dset")
+	if err := SaveStrings(path, strs); err != nil {
+		t.Fatal(err)
+	}
+	gotStrs, err := LoadStrings(path)
+	if err != nil || !slices.Equal(gotStrs, strs) {
+		t.Errorf("strings did not round trip: %v", err)
+	}
+
+	if _, err := LoadInts(path); !errors.Is(err, ErrFormat) {
+		t.Errorf("expected ErrFormat loading strings as ints but got %v", err)
+	}
+}
+
+func Test_ReadInts_Corrupt(t *testing.T) {
+	var buf bytes.Buffer
+	WriteInts(&buf, []int{1, 2, 3, 300})
+	data := buf.Bytes()
+
+	for name, bad := range map[string][]byte{
+		"empty":     nil,
+		"magic":     append([]byte("XSET"), data[4:]...),
+		"truncated": data[:len(data)-5],
+		"flipped":   append(slices.Clone(data[:len(data)-6]), data[len(data)-6]^1, data[len(data)-5], data[len(data)-4], data[len(data)-3], data[len(data)-2], data[len(data)-1]),
+	} {
+		if _, err := ReadInts(bytes.NewReader(bad)); !errors.Is(err, ErrFormat) {
+			t.Errorf("%s: expected ErrFormat but got %v", name, err)
+		}
+	}
+}
+
+func Benchmark_Ints(b *testing.B) {
+	for _, d := range Dists {
+		b.Run(d.String(), func(b *testing.B) {
+			g := New(1)
+			s := make([]int, 1<<16)
+			for i := 0; i < b.N; i++ {
+				g.Fill(d, s)
+			}
+		})
+	}
+}
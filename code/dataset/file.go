@@ -0,0 +1,203 @@
+package dataset
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"strings"
+)
+
+// The file format is
+//
+//	"DSET" | version byte | kind byte | uvarint count | values | crc32
+//
+// with ints as zig-zag varints and strings as a uvarint length followed by
+// the bytes. The big-endian CRC-32 (IEEE) covers everything before it.
+const (
+	magic   = "DSET"
+	version = 1
+
+	kindInts    = 'i'
+	kindStrings = 's'
+)
+
+// ErrFormat is returned when a file is not a dataset of the expected kind
+// or fails its checksum.
+var ErrFormat = errors.New("dataset: invalid file")
+
+// WriteInts writes s to w in the dataset format.
+func WriteInts(w io.Writer, s []int) error {
+	buf := header(kindInts, len(s))
+	for _, v := range s {
+		buf = binary.AppendVarint(buf, int64(v))
+	}
+	return writeWithChecksum(w, buf)
+}
+
+// WriteStrings writes s to w in the dataset format.
+func WriteStrings(w io.Writer, s []string) error {
+	buf := header(kindStrings, len(s))
+	for _, v := range s {
+		buf = binary.AppendUvarint(buf, uint64(len(v)))
+		buf = append(buf, v...)
+	}
+	return writeWithChecksum(w, buf)
+}
+
+func header(kind byte, n int) []byte {
+	buf := append([]byte(magic), version, kind)
+	return binary.AppendUvarint(buf, uint64(n))
+}
+
+func writeWithChecksum(w io.Writer, buf []byte) error {
+	buf = binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadInts reads a dataset written by WriteInts.
+func ReadInts(r io.Reader) ([]int, error) {
+	cr, n, err := readHeader(r, kindInts)
+	if err != nil {
+		return nil, err
+	}
+	s := make([]int, 0, min(n, 1<<20))
+	for i := uint64(0); i < n; i++ {
+		v, err := binary.ReadVarint(cr)
+		if err != nil {
+			return nil, formatError(err)
+		}
+		s = append(s, int(v))
+	}
+	return s, cr.verify()
+}
+
+// ReadStrings reads a dataset written by WriteStrings.
+func ReadStrings(r io.Reader) ([]string, error) {
+	cr, n, err := readHeader(r, kindStrings)
+	if err != nil {
+		return nil, err
+	}
+	s := make([]string, 0, min(n, 1<<20))
+	for i := uint64(0); i < n; i++ {
+		l, err := binary.ReadUvarint(cr)
+		if err != nil {
+			return nil, formatError(err)
+		}
+		if int64(l) < 0 {
+			return nil, ErrFormat
+		}
+		// CopyN grows the string as the bytes arrive, so a corrupt length
+		// cannot make it allocate more than the file holds.
+		var sb strings.Builder
+		if _, err := io.CopyN(&sb, cr, int64(l)); err != nil {
+			return nil, formatError(err)
+		}
+		s = append(s, sb.String())
+	}
+	return s, cr.verify()
+}
+
+func readHeader(r io.Reader, kind byte) (*checksumReader, uint64, error) {
+	cr := &checksumReader{r: bufio.NewReader(r)}
+	h := make([]byte, len(magic)+2)
+	if _, err := io.ReadFull(cr, h); err != nil {
+		return nil, 0, formatError(err)
+	}
+	if string(h[:len(magic)]) != magic || h[len(magic)] != version {
+		return nil, 0, ErrFormat
+	}
+	if h[len(magic)+1] != kind {
+		return nil, 0, fmt.Errorf("%w: holds kind %q, not %q", ErrFormat, h[len(magic)+1], kind)
+	}
+	n, err := binary.ReadUvarint(cr)
+	if err != nil {
+		return nil, 0, formatError(err)
+	}
+	return cr, n, nil
+}
+
+func formatError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: truncated", ErrFormat)
+	}
+	return err
+}
+
+// checksumReader hashes everything read through it.
+type checksumReader struct {
+	r   *bufio.Reader
+	crc uint32
+}
+
+func (cr *checksumReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.crc = crc32.Update(cr.crc, crc32.IEEETable, p[:n])
+	return n, err
+}
+
+func (cr *checksumReader) ReadByte() (byte, error) {
+	b, err := cr.r.ReadByte()
+	if err == nil {
+		cr.crc = crc32.Update(cr.crc, crc32.IEEETable, []byte{b})
+	}
+	return b, err
+}
+
+// verify reads the trailing checksum and compares it with the data read.
+func (cr *checksumReader) verify() error {
+	var sum [4]byte
+	if _, err := io.ReadFull(cr.r, sum[:]); err != nil {
+		return formatError(err)
+	}
+	if binary.BigEndian.Uint32(sum[:]) != cr.crc {
+		return fmt.Errorf("%w: checksum mismatch", ErrFormat)
+	}
+	return nil
+}
+
+// SaveInts writes s to the file at path.
+func SaveInts(path string, s []int) error {
+	return save(path, func(w io.Writer) error { return WriteInts(w, s) })
+}
+
+// SaveStrings writes s to the file at path.
+func SaveStrings(path string, s []string) error {
+	return save(path, func(w io.Writer) error { return WriteStrings(w, s) })
+}
+
+func save(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadInts reads the file at path written by SaveInts.
+func LoadInts(path string) ([]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadInts(f)
+}
+
+// LoadStrings reads the file at path written by SaveStrings.
+func LoadStrings(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadStrings(f)
+}
@@ -0,0 +1,57 @@
+// Extsort sorts a file that may not fit in memory, one record per line.
+//
+// go run ./cmd/extsort -ints -mem 512 -o sorted.txt numbers.txt
+// go run ./cmd/extsort -mem 64 -fanin 16 -tmp /scratch < records.txt > sorted.txt
+package main
+
+import (
+	"bufio"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/sathishvj/optimizing-go-programs/code/extsort"
+)
+
+func main() {
+	var (
+		ints  = flag.Bool("ints", false, "sort numerically, one integer per line")
+		mem   = flag.Int64("mem", 64, "memory budget in MiB")
+		fanIn = flag.Int("fanin", 64, "runs merged at once")
+		tmp   = flag.String("tmp", "", "directory for the sorted runs (default the system temp dir)")
+		out   = flag.String("o", "", "output file (default stdout)")
+	)
+	flag.Parse()
+
+	var src io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		src = bufio.NewReaderSize(f, 1<<20)
+	}
+
+	dst := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		dst = f
+	}
+
+	c := extsort.Config{MemoryBudget: *mem << 20, MaxFanIn: *fanIn, TempDir: *tmp}
+	sort := extsort.SortLines
+	if *ints {
+		sort = extsort.SortInts
+	}
+	if err := sort(dst, src, c); err != nil {
+		log.Fatal(err)
+	}
+	if err := dst.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
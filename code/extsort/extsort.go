@@ -0,0 +1,300 @@
+// Package extsort sorts newline-delimited files that do not fit in memory.
+//
+// The input is read in chunks that fit the memory budget. Each chunk is
+// sorted in parallel with mergesort and spilled to a temporary file as a
+// sorted run, and the runs are then merged k ways through a heap. With
+// more runs than MaxFanIn, the merge takes several passes.
+//
+//	err := extsort.SortInts(dst, src, extsort.Config{MemoryBudget: 256 << 20})
+package extsort
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"container/heap"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort"
+)
+
+// Config controls the resources a sort may use.
+type Config struct {
+	// MemoryBudget is the approximate number of bytes of records, including
+	// bookkeeping, held in memory at once. Default 64MiB.
+	MemoryBudget int64
+	// MaxFanIn is how many runs are merged at once, which is also how many
+	// temporary files are open at once. Default 64.
+	MaxFanIn int
+	// TempDir holds the sorted runs. Default os.TempDir().
+	TempDir string
+}
+
+func (c Config) withDefaults() Config {
+	if c.MemoryBudget <= 0 {
+		c.MemoryBudget = 64 << 20
+	}
+	if c.MaxFanIn < 2 {
+		c.MaxFanIn = 64
+	}
+	return c
+}
+
+// maxLine is the longest record a sort accepts.
+const maxLine = 64 << 20
+
+// SortLines sorts the lines of src bytewise into dst. Every output line,
+// including the last, ends in a newline. Lines are split as bufio.ScanLines
+// splits them, which drops a \r before the newline, so CRLF input comes out
+// with LF line endings and a \r is not part of the sorted record.
+func SortLines(dst io.Writer, src io.Reader, c Config) error {
+	return sortRecords(dst, src, c, codec[string]{
+		parse:  func(line []byte) (string, error) { return string(line), nil },
+		append: func(b []byte, s string) []byte { return append(b, s...) },
+		size:   func(s string) int64 { return int64(len(s)) + 2*16 },
+		cmp:    strings.Compare,
+	})
+}
+
+// SortInts sorts src, one decimal integer per line, numerically into dst.
+func SortInts(dst io.Writer, src io.Reader, c Config) error {
+	return sortRecords(dst, src, c, codec[int]{
+		parse: func(line []byte) (int, error) {
+			return strconv.Atoi(string(bytes.TrimSpace(line)))
+		},
+		append: func(b []byte, v int) []byte { return strconv.AppendInt(b, int64(v), 10) },
+		size:   func(int) int64 { return 2 * 8 },
+		cmp:    cmp.Compare[int],
+	})
+}
+
+// codec describes one record type. size estimates the memory a record
+// takes while its chunk is sorted, including the ping-pong scratch copy.
+type codec[T any] struct {
+	parse  func(line []byte) (T, error)
+	append func(b []byte, v T) []byte
+	size   func(v T) int64
+	cmp    func(a, b T) int
+}
+
+func sortRecords[T any](dst io.Writer, src io.Reader, c Config, rc codec[T]) error {
+	c = c.withDefaults()
+
+	var runs []string
+	defer func() {
+		for _, r := range runs {
+			os.Remove(r)
+		}
+	}()
+
+	sc := bufio.NewScanner(src)
+	sc.Buffer(make([]byte, 64<<10), maxLine)
+
+	var chunk []T
+	var used int64
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		v, err := rc.parse(sc.Bytes())
+		if err != nil {
+			return fmt.Errorf("extsort: line %d: %v", lineNo, err)
+		}
+		chunk = append(chunk, v)
+		used += rc.size(v)
+
+		if used >= c.MemoryBudget {
+			run, err := spill(chunk, c.TempDir, rc)
+			if err != nil {
+				return err
+			}
+			runs = append(runs, run)
+			// Let go of the spilled records, or the reused array keeps them
+			// alive next to the next chunk's.
+			clear(chunk)
+			chunk, used = chunk[:0], 0
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("extsort: line %d: %v", lineNo+1, err)
+	}
+
+	// Everything fit in memory: no temporary files needed.
+	if len(runs) == 0 {
+		mergesort.SortFunc(chunk, rc.cmp, mergesort.WithStrategy(mergesort.V4), mergesort.WithMergeMode(mergesort.PingPong))
+		w := bufio.NewWriter(dst)
+		var line []byte
+		for _, v := range chunk {
+			line = append(rc.append(line[:0], v), '\n')
+			if _, err := w.Write(line); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	}
+
+	if len(chunk) > 0 {
+		run, err := spill(chunk, c.TempDir, rc)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, run)
+	}
+	chunk = nil
+
+	// Merge groups of runs into longer ones until one pass is enough.
+	for len(runs) > c.MaxFanIn {
+		var next []string
+		for i := 0; i < len(runs); i += c.MaxFanIn {
+			group := runs[i:min(i+c.MaxFanIn, len(runs))]
+			f, err := os.CreateTemp(c.TempDir, "extsort-*.run")
+			if err != nil {
+				return err
+			}
+			next = append(next, f.Name())
+			err = mergeRuns(f, group, rc)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				runs = append(next, runs[i:]...) // leave the rest to be removed
+				return err
+			}
+			for _, r := range group {
+				os.Remove(r)
+			}
+		}
+		runs = next
+	}
+	return mergeRuns(dst, runs, rc)
+}
+
+// spill sorts chunk and writes it to a new temporary file, returning its
+// name.
+func spill[T any](chunk []T, dir string, rc codec[T]) (string, error) {
+	mergesort.SortFunc(chunk, rc.cmp, mergesort.WithStrategy(mergesort.V4), mergesort.WithMergeMode(mergesort.PingPong))
+
+	f, err := os.CreateTemp(dir, "extsort-*.run")
+	if err != nil {
+		return "", err
+	}
+	w := bufio.NewWriterSize(f, 1<<20)
+	var line []byte
+	for _, v := range chunk {
+		line = append(rc.append(line[:0], v), '\n')
+		if _, err = w.Write(line); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// mergeRuns k-way merges the sorted run files into dst.
+func mergeRuns[T any](dst io.Writer, runs []string, rc codec[T]) error {
+	h := &runHeap[T]{cmp: rc.cmp}
+	for _, name := range runs {
+		f, err := os.Open(name)
+		if err != nil {
+			h.close()
+			return err
+		}
+		r := &run[T]{f: f, sc: bufio.NewScanner(bufio.NewReaderSize(f, 256<<10))}
+		r.sc.Buffer(make([]byte, 64<<10), maxLine)
+		h.runs = append(h.runs, r)
+	}
+	defer h.close()
+
+	// prime every run with its first record
+	live := make([]*run[T], 0, len(h.runs))
+	for _, r := range h.runs {
+		ok, err := r.next(rc)
+		if err != nil {
+			return err
+		}
+		if ok {
+			live = append(live, r)
+		} else {
+			r.f.Close()
+		}
+	}
+	h.runs = live
+	heap.Init(h)
+
+	w := bufio.NewWriterSize(dst, 1<<20)
+	var line []byte
+	for h.Len() > 0 {
+		r := h.runs[0]
+		line = append(rc.append(line[:0], r.head), '\n')
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+
+		ok, err := r.next(rc)
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			r.f.Close()
+			heap.Pop(h)
+		}
+	}
+	return w.Flush()
+}
+
+// run is an open sorted run and its smallest unmerged record.
+type run[T any] struct {
+	f    *os.File
+	sc   *bufio.Scanner
+	head T
+}
+
+func (r *run[T]) next(rc codec[T]) (bool, error) {
+	if !r.sc.Scan() {
+		return false, r.sc.Err()
+	}
+	v, err := rc.parse(r.sc.Bytes())
+	if err != nil {
+		return false, fmt.Errorf("extsort: %s: %v", r.f.Name(), err)
+	}
+	r.head = v
+	return true, nil
+}
+
+// runHeap orders runs by their head record.
+type runHeap[T any] struct {
+	runs []*run[T]
+	cmp  func(a, b T) int
+}
+
+func (h *runHeap[T]) Len() int { return len(h.runs) }
+func (h *runHeap[T]) Less(i, j int) bool {
+	return h.cmp(h.runs[i].head, h.runs[j].head) < 0
+}
+func (h *runHeap[T]) Swap(i, j int) { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+func (h *runHeap[T]) Push(x any)    { h.runs = append(h.runs, x.(*run[T])) }
+func (h *runHeap[T]) Pop() any {
+	r := h.runs[len(h.runs)-1]
+	h.runs = h.runs[:len(h.runs)-1]
+	return r
+}
+
+func (h *runHeap[T]) close() {
+	for _, r := range h.runs {
+		r.f.Close()
+	}
+}
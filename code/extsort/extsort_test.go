@@ -0,0 +1,113 @@
+package extsort
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sathishvj/optimizing-go-programs/code/dataset"
+)
+
+// intsFile returns n generated ints, about half of them negative, as lines.
+func intsFile(n int) ([]int, []byte) {
+	s := dataset.New(1).Ints(dataset.Uniform, n)
+	var b []byte
+	for i := range s {
+		s[i] -= n / 2
+		b = strconv.AppendInt(b, int64(s[i]), 10)
+		b = append(b, '\n')
+	}
+	return s, b
+}
+
+func noRunsLeft(t *testing.T, dir string) {
+	t.Helper()
+	left, _ := filepath.Glob(filepath.Join(dir, "extsort-*.run"))
+	if len(left) > 0 {
+		t.Errorf("temporary runs left behind: %v", left)
+	}
+}
+
+func Test_SortInts(t *testing.T) {
+	s, in := intsFile(100000)
+	want := slices.Clone(s)
+	slices.Sort(want)
+
+	for _, c := range []Config{
+		{},                                    // one chunk, no temporary files
+		{MemoryBudget: 64 << 10},              // 25 runs, one merge pass
+		{MemoryBudget: 16 << 10, MaxFanIn: 3}, // 98 runs, several passes
+	} {
+		c.TempDir = t.TempDir()
+		var out bytes.Buffer
+		if err := SortInts(&out, bytes.NewReader(in), c); err != nil {
+			t.Fatalf("%+v: %v", c, err)
+		}
+
+		var got []int
+		for _, f := range strings.Fields(out.String()) {
+			v, _ := strconv.Atoi(f)
+			got = append(got, v)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%+v: output differs from an in-memory sort", c)
+		}
+		noRunsLeft(t, c.TempDir)
+	}
+}
+
+func Test_SortLines(t *testing.T) {
+	s := dataset.New(2).Strings(20000, 12, "abc")
+	want := slices.Clone(s)
+	slices.Sort(want)
+
+	c := Config{MemoryBudget: 32 << 10, MaxFanIn: 4, TempDir: t.TempDir()}
+	var out bytes.Buffer
+	if err := SortLines(&out, strings.NewReader(strings.Join(s, "\n")), c); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"); !slices.Equal(got, want) {
+		t.Errorf("output differs from an in-memory sort")
+	}
+	noRunsLeft(t, c.TempDir)
+}
+
+// Test_SortLines_CRLF pins down that a \r before the newline is dropped,
+// as bufio.ScanLines does.
+func Test_SortLines_CRLF(t *testing.T) {
+	var out bytes.Buffer
+	if err := SortLines(&out, strings.NewReader("b\r\na\r\n"), Config{}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "a\nb\n" {
+		t.Errorf("expected %q but got %q", "a\nb\n", out.String())
+	}
+}
+
+func Test_SortInts_Invalid(t *testing.T) {
+	_, in := intsFile(10000)
+	in = append(in, "12x\n"...)
+
+	c := Config{MemoryBudget: 16 << 10, TempDir: t.TempDir()}
+	err := SortInts(&bytes.Buffer{}, bytes.NewReader(in), c)
+	if err == nil || !strings.Contains(err.Error(), "line 10001") {
+		t.Errorf("got %v, want an error for line 10001", err)
+	}
+	noRunsLeft(t, c.TempDir)
+}
+
+func Benchmark_SortInts(b *testing.B) {
+	_, in := intsFile(1 << 20)
+	dir := b.TempDir()
+	b.SetBytes(int64(len(in)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := SortInts(io.Discard, bytes.NewReader(in), Config{MemoryBudget: 1 << 20, TempDir: dir}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -8,142 +8,19 @@ package main
 
 import (
 	"fmt"
-	"math/rand"
 	"os"
 	"runtime/trace"
-	"sync"
-	"time"
-)
-
-const max = 1 << 11
-
-func merge(s []int, middle int) {
-	helper := make([]int, len(s))
-	copy(helper, s)
-
-	helperLeft := 0
-	helperRight := middle
-	current := 0
-	high := len(s) - 1
-
-	for helperLeft <= middle-1 && helperRight <= high {
-		if helper[helperLeft] <= helper[helperRight] {
-			s[current] = helper[helperLeft]
-			helperLeft++
-		} else {
-			s[current] = helper[helperRight]
-			helperRight++
-		}
-		current++
-	}
-
-	for helperLeft <= middle-1 {
-		s[current] = helper[helperLeft]
-		current++
-		helperLeft++
-	}
-}
-
-func mergesortv1(s []int) {
-	len := len(s)
-
-	if len > 1 {
-		middle := len / 2
-
-		var wg sync.WaitGroup
-		wg.Add(2)
-
-		// First half
-		go func() {
-			defer wg.Done()
-			mergesortv1(s[:middle])
-		}()
-
-		// Second half
-		go func() {
-			defer wg.Done()
-			mergesortv1(s[middle:])
-		}()
-
-		// Wait that the two goroutines are completed
-		wg.Wait()
-		merge(s, middle)
-	}
-}
-
-/* Sequential */
-
-func mergesort(s []int) {
-	if len(s) > 1 {
-		middle := len(s) / 2
-		mergesort(s[:middle])
-		mergesort(s[middle:])
-		merge(s, middle)
-	}
-}
-
-func mergesortv2(s []int) {
-	len := len(s)
-
-	if len > 1 {
-		if len <= max { // Sequential
-			mergesort(s)
-		} else { // Parallel
-			middle := len / 2
 
-			var wg sync.WaitGroup
-			wg.Add(2)
-
-			go func() {
-				defer wg.Done()
-				mergesortv2(s[:middle])
-			}()
-
-			go func() {
-				defer wg.Done()
-				mergesortv2(s[middle:])
-			}()
-
-			wg.Wait()
-			merge(s, middle)
-		}
-	}
-}
-
-func mergesortv3(s []int) {
-	len := len(s)
-
-	if len > 1 {
-		if len <= max { // Sequential
-			mergesort(s)
-		} else { // Parallel
-			middle := len / 2
-
-			var wg sync.WaitGroup
-			wg.Add(1)
-
-			go func() {
-				defer wg.Done()
-				mergesortv3(s[:middle])
-			}()
-
-			mergesortv3(s[middle:])
+	"github.com/sathishvj/optimizing-go-programs/code/dataset"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort"
+)
 
-			wg.Wait()
-			merge(s, middle)
-		}
-	}
-}
+// seeded, so every run sorts the same slices
+var gen = dataset.New(1)
 
 // Generates a slice of size, size filled with random numbers
 func generateSlice(size int) []int {
-
-	slice := make([]int, size, size)
-	rand.Seed(time.Now().UnixNano())
-	for i := 0; i < size; i++ {
-		slice[i] = rand.Intn(999) - rand.Intn(999)
-	}
-	return slice
+	return gen.Ints(dataset.Uniform, size)
 }
 
 func main() {
@@ -152,6 +29,12 @@ func main() {
 		version = os.Args[1]
 	}
 
+	strategy, err := mergesort.ParseStrategy(version)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
 	f, err := os.OpenFile(version+".trace", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
 	if err != nil {
 		fmt.Println("Error:", err)
@@ -163,15 +46,6 @@ func main() {
 
 	for i := 0; i < 10000; i++ {
 		s := generateSlice(10)
-
-		switch version {
-		case "v1":
-			mergesortv1(s)
-		case "v2":
-			mergesortv2(s)
-		case "v3":
-			mergesortv3(s)
-		}
+		mergesort.Sort(s, mergesort.WithStrategy(strategy))
 	}
-
 }
@@ -0,0 +1,60 @@
+// Sweep runs the gogc mergesort workload once per GOGC value, and optionally
+// per memory limit, inside one process, instead of one
+// `GOGC=x GOMEMLIMIT=y go run mergesort.go` per setting, and prints the
+// runtime/metrics numbers as a table.
+//
+// go run ./sweep
+// go run ./sweep -gogc off,25,50,100,200,400 -version v3 -n 100000 -iters 50
+// go run ./sweep -gogc off -memlimit 4MiB,8MiB,16MiB,32MiB
+// go run ./sweep -gogc off,100 -memlimit off,8MiB -format csv > gogc.csv
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/sathishvj/optimizing-go-programs/code/dataset"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort/experiment"
+)
+
+func main() {
+	w := experiment.Workload{
+		Strategy:       mergesort.V1,
+		Size:           10,
+		Iterations:     10000,
+		Dist:           dataset.Uniform,
+		Seed:           1,
+		SampleInterval: time.Millisecond,
+	}
+	w.Flags(flag.CommandLine)
+
+	var (
+		gogc     = flag.String("gogc", "off,50,100,200", "comma separated GOGC values")
+		memlimit = flag.String("memlimit", "", "comma separated memory limits, e.g. 8MiB,off; every GOGC value runs with every limit")
+		format   = flag.String("format", "md", "output format: md, csv, text")
+	)
+	flag.Parse()
+
+	percents, err := experiment.ParseGCPercents(*gogc)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var limits []int64
+	if *memlimit != "" {
+		if limits, err = experiment.ParseMemoryLimits(*memlimit); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	runs, err := experiment.GCSweep(w, experiment.GCSettings(percents, limits))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := experiment.GCTable(runs).Write(os.Stdout, *format); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -1,22 +1,36 @@
+// Each benchmark sorts fresh copies of generated data, per size and
+// distribution (see mergesort/sorttest):
+//
+// go test -run none -bench 'v3/n=1000000/' mergesort.go mergesort_test.go
 package main
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort/sorttest"
+)
 
 func Benchmark_mergesortv1(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		mergesortv1(s)
-	}
+	sorttest.Benchmark(b, func(s []int) {
+		mergesort.Sort(s, mergesort.WithStrategy(mergesort.V1))
+	})
 }
 
 func Benchmark_mergesortv2(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		mergesortv2(s)
-	}
+	sorttest.Benchmark(b, func(s []int) {
+		mergesort.Sort(s, mergesort.WithStrategy(mergesort.V2))
+	})
 }
 
-
 func Benchmark_mergesortv3(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		mergesortv3(s)
-	}
-}
\ No newline at end of file
+	sorttest.Benchmark(b, func(s []int) {
+		mergesort.Sort(s, mergesort.WithStrategy(mergesort.V3))
+	})
+}
+
+func Benchmark_mergesortv4(b *testing.B) {
+	sorttest.Benchmark(b, func(s []int) {
+		mergesort.Sort(s, mergesort.WithStrategy(mergesort.V4))
+	})
+}
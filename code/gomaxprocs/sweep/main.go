@@ -0,0 +1,61 @@
+// Sweep runs every mergesort version under GOMAXPROCS=n for n = 1 to
+// runtime.NumCPU() and reports the median time, the speedup over n=1 and the
+// parallel efficiency, to answer "What should be the value of GOMAXPROCS?"
+// with numbers rather than traces.
+//
+// go run ./sweep
+// go run ./sweep -versions v2,v3,v4 -procs 1,2,4,8,16 -repeat 7 -svg speedup.svg
+// go run ./sweep -n 10000000 -format md
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/sathishvj/optimizing-go-programs/code/dataset"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort/experiment"
+)
+
+func main() {
+	w := experiment.Workload{Strategy: mergesort.V3, Size: 1 << 18, Iterations: 1, Dist: dataset.Uniform, Seed: 1}
+	w.SweepFlags(flag.CommandLine) // the versions come from -versions
+
+	var (
+		versions = flag.String("versions", "v1,v2,v3,v4", "comma separated mergesort versions to compare")
+		procs    = flag.String("procs", "", "GOMAXPROCS values, e.g. 1,2,4 or 1-8 (default 1 to NumCPU)")
+		repeat   = flag.Int("repeat", 5, "runs per version and GOMAXPROCS; the median counts")
+		format   = flag.String("format", "text", "table format: text, md, csv")
+		svg      = flag.String("svg", "", "also draw the speedup as an SVG line chart to this file")
+	)
+	flag.Parse()
+
+	strategies, err := experiment.ParseStrategies(*versions)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ns, err := experiment.ParseProcs(*procs)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	runs, err := experiment.ProcsSweep(w, strategies, ns, *repeat)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := experiment.ProcsTable(runs).Write(os.Stdout, *format); err != nil {
+		log.Fatal(err)
+	}
+
+	if *svg != "" {
+		f, err := os.Create(*svg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		if err := experiment.WriteSpeedupSVG(f, runs); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -1,19 +1,23 @@
 package main
 
 import (
-	"math/rand"
 	"strconv"
 	"testing"
+
+	"github.com/sathishvj/optimizing-go-programs/code/dataset"
 )
 
 var NumItems int = 1000000
 
+// the same keys on every run, for both key types
+var keys = dataset.New(1).Ints(dataset.Uniform, NumItems)
+
 func BenchmarkMapStringKeys(b *testing.B) {
 	m := make(map[string]string)
 	k := make([]string, 0)
 
 	for i := 0; i < NumItems; i++ {
-		key := strconv.Itoa(rand.Intn(NumItems))
+		key := strconv.Itoa(keys[i])
 		//key += ` is the key value that is being used. `
 		key += ` is the key value that is being used and a shakespeare sonnet. ` + sonnet106
 		m[key] = "value" + strconv.Itoa(i)
@@ -40,7 +44,7 @@ func BenchmarkMapIntKeys(b *testing.B) {
 	k := make([]int, 0)
 
 	for i := 0; i < NumItems; i++ {
-		key := rand.Intn(NumItems)
+		key := keys[i]
 		m[key] = "value" + strconv.Itoa(i)
 		k = append(k, key)
 	}
@@ -0,0 +1,178 @@
+package mergesort
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// calibrated holds the thresholds UseCalibration loaded, by strategy, and 0
+// for a strategy with none.
+var calibrated [V4 + 1]atomic.Int64
+
+// defaultThreshold returns the threshold of a sort with strategy st that is
+// not given WithThreshold: the calibrated one, or DefaultThreshold.
+func defaultThreshold(st Strategy) int {
+	if st >= 0 && int(st) < len(calibrated) {
+		if t := calibrated[st].Load(); t > 0 {
+			return int(t)
+		}
+	}
+	return DefaultThreshold
+}
+
+// usesThreshold reports whether the threshold changes how st sorts. The
+// other strategies ignore it, so calibrating them only measures noise.
+func usesThreshold(st Strategy) bool {
+	return st == V2 || st == V3
+}
+
+// Candidates returns the powers of two from 1<<lo to 1<<hi, the usual
+// thresholds to try in Calibrate.
+func Candidates(lo, hi int) []int {
+	var c []int
+	for i := lo; i <= hi; i++ {
+		c = append(c, 1<<i)
+	}
+	return c
+}
+
+// Point is one measurement on the threshold curve.
+type Point struct {
+	Threshold int           `json:"threshold"`
+	Time      time.Duration `json:"time_ns"`
+}
+
+// Calibration is the fastest threshold found for one machine, together with
+// the curve it was picked from.
+type Calibration struct {
+	Threshold  int     `json:"threshold"`
+	Strategy   string  `json:"strategy"`
+	GOMAXPROCS int     `json:"gomaxprocs"`
+	GOARCH     string  `json:"goarch"`
+	CPU        string  `json:"cpu"`
+	Size       int     `json:"size"`
+	Curve      []Point `json:"curve"`
+}
+
+// Calibrate sorts a copy of sample rounds times with each candidate
+// threshold and returns the one with the fastest run. opts are applied
+// before the threshold, so they can pick the strategy and merge mode being
+// calibrated; the default strategy is V3. Only V2 and V3 use the threshold,
+// so any other strategy is an error.
+func Calibrate(sample []int, candidates []int, rounds int, opts ...Option) (Calibration, error) {
+	c := config{strategy: V3}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if !usesThreshold(c.strategy) {
+		return Calibration{}, fmt.Errorf("mergesort: cannot calibrate %v, only v2 and v3 use the threshold", c.strategy)
+	}
+
+	cal := Calibration{
+		Strategy:   c.strategy.String(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		GOARCH:     runtime.GOARCH,
+		CPU:        cpuModel(),
+		Size:       len(sample),
+	}
+
+	s := make([]int, len(sample))
+	for _, threshold := range candidates {
+		best := time.Duration(-1)
+		for i := 0; i < max(rounds, 1); i++ {
+			copy(s, sample)
+			start := time.Now()
+			Sort(s, append(opts[:len(opts):len(opts)], WithThreshold(threshold))...)
+			if d := time.Since(start); best < 0 || d < best {
+				best = d
+			}
+		}
+		cal.Curve = append(cal.Curve, Point{threshold, best})
+	}
+
+	if len(cal.Curve) > 0 {
+		fastest := slices.MinFunc(cal.Curve, func(a, b Point) int { return cmp.Compare(a.Time, b.Time) })
+		cal.Threshold = fastest.Threshold
+	}
+	return cal, nil
+}
+
+// Matches reports whether cal was measured on a machine like this one: same
+// GOMAXPROCS, architecture and CPU model.
+func (cal Calibration) Matches() bool {
+	return cal.GOMAXPROCS == runtime.GOMAXPROCS(0) &&
+		cal.GOARCH == runtime.GOARCH &&
+		cal.CPU == cpuModel()
+}
+
+// Save writes cal to path as indented JSON.
+func (cal Calibration) Save(path string) error {
+	data, err := json.MarshalIndent(cal, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// LoadCalibration reads a calibration written by Save.
+func LoadCalibration(path string) (Calibration, error) {
+	var cal Calibration
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cal, err
+	}
+	if err := json.Unmarshal(data, &cal); err != nil {
+		return cal, fmt.Errorf("mergesort: %s: %v", path, err)
+	}
+	if cal.Threshold < 1 {
+		return cal, fmt.Errorf("mergesort: %s: invalid threshold %d", path, cal.Threshold)
+	}
+	if st, err := ParseStrategy(cal.Strategy); err != nil || !usesThreshold(st) {
+		return cal, fmt.Errorf("mergesort: %s: strategy %q has no threshold to calibrate", path, cal.Strategy)
+	}
+	return cal, nil
+}
+
+// UseCalibration loads the calibration at path and makes its threshold the
+// default for every later sort with the calibrated strategy that is not
+// given WithThreshold. A calibration taken on a different machine or
+// GOMAXPROCS is rejected, and the current default is kept.
+func UseCalibration(path string) error {
+	cal, err := LoadCalibration(path)
+	if err != nil {
+		return err
+	}
+	if !cal.Matches() {
+		return fmt.Errorf("mergesort: %s was calibrated for GOMAXPROCS=%d on %s %q",
+			path, cal.GOMAXPROCS, cal.GOARCH, cal.CPU)
+	}
+	st, _ := ParseStrategy(cal.Strategy) // checked by LoadCalibration
+	calibrated[st].Store(int64(cal.Threshold))
+	return nil
+}
+
+// cpuModel returns the CPU model name, or "" where /proc/cpuinfo is not
+// available.
+func cpuModel() string {
+	f, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if k, v, ok := strings.Cut(sc.Text(), ":"); ok && strings.TrimSpace(k) == "model name" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
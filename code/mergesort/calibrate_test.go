@@ -0,0 +1,74 @@
+package mergesort
+
+import (
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func Test_Calibrate(t *testing.T) {
+	candidates := Candidates(4, 8)
+	if !slices.Equal(candidates, []int{16, 32, 64, 128, 256}) {
+		t.Fatalf("unexpected candidates %v", candidates)
+	}
+
+	cal, err := Calibrate(randomInts(1<<12), candidates, 2, WithStrategy(V2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cal.Curve) != len(candidates) {
+		t.Fatalf("expected %d points but got %d", len(candidates), len(cal.Curve))
+	}
+	if !slices.Contains(candidates, cal.Threshold) {
+		t.Errorf("threshold %d is not one of the candidates", cal.Threshold)
+	}
+	if cal.Strategy != "v2" || cal.GOMAXPROCS != runtime.GOMAXPROCS(0) || !cal.Matches() {
+		t.Errorf("unexpected calibration %+v", cal)
+	}
+
+	for _, st := range []Strategy{Sequential, V1, V4} {
+		if _, err := Calibrate(randomInts(1<<10), candidates, 1, WithStrategy(st)); err == nil {
+			t.Errorf("expected an error calibrating %v, which ignores the threshold", st)
+		}
+	}
+}
+
+func Test_UseCalibration(t *testing.T) {
+	defer calibrated[V3].Store(0)
+
+	path := filepath.Join(t.TempDir(), "calibration.json")
+	cal, err := Calibrate(randomInts(1<<10), []int{64}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cal.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := UseCalibration(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := defaultThreshold(V3); got != 64 {
+		t.Errorf("expected threshold 64 but got %d", got)
+	}
+	if got := defaultThreshold(V2); got != DefaultThreshold {
+		t.Errorf("expected v2 to keep threshold %d but got %d", DefaultThreshold, got)
+	}
+
+	v1 := cal
+	v1.Strategy = "v1"
+	if err := v1.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := UseCalibration(path); err == nil {
+		t.Errorf("expected a calibration of v1 to be rejected")
+	}
+
+	cal.GOMAXPROCS++
+	if err := cal.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := UseCalibration(path); err == nil {
+		t.Errorf("expected a calibration for another GOMAXPROCS to be rejected")
+	}
+}
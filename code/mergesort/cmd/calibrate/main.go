@@ -0,0 +1,70 @@
+// Calibrate measures how fast mergesort sorts a sample for every candidate
+// sequential threshold, prints the threshold-vs-time curve and saves the
+// fastest threshold for mergesort.UseCalibration.
+//
+// go run ./cmd/calibrate
+// go run ./cmd/calibrate -strategy v2 -n 1000000 -lo 6 -hi 16 -o calibration.json
+// GOMAXPROCS=8 go run ./cmd/calibrate -o ""    (print only)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/sathishvj/optimizing-go-programs/code/dataset"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort"
+)
+
+func main() {
+	var (
+		version = flag.String("strategy", "v3", "strategy to calibrate: v2, v3")
+		n       = flag.Int("n", 1<<20, "sample size")
+		seed    = flag.Uint64("seed", 1, "sample seed")
+		lo      = flag.Int("lo", 6, "smallest candidate threshold, as a power of two")
+		hi      = flag.Int("hi", 16, "largest candidate threshold, as a power of two")
+		rounds  = flag.Int("rounds", 5, "sorts per candidate; the fastest one counts")
+		out     = flag.String("o", "calibration.json", "where to save the result, empty to skip")
+	)
+	flag.Parse()
+
+	strategy, err := mergesort.ParseStrategy(*version)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sample := dataset.New(*seed).Ints(dataset.Uniform, *n)
+
+	candidates := mergesort.Candidates(*lo, *hi)
+	if len(candidates) == 0 {
+		log.Fatalf("no candidates between 1<<%d and 1<<%d", *lo, *hi)
+	}
+	cal, err := mergesort.Calibrate(sample, candidates, *rounds, mergesort.WithStrategy(strategy))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("strategy=%s n=%d GOMAXPROCS=%d cpu=%q\n\n", cal.Strategy, cal.Size, cal.GOMAXPROCS, cal.CPU)
+	fmt.Printf("%9s  %12s\n", "threshold", "time")
+	var slowest time.Duration
+	for _, p := range cal.Curve {
+		slowest = max(slowest, p.Time)
+	}
+	for _, p := range cal.Curve {
+		bar := strings.Repeat("#", int(40*p.Time/slowest))
+		mark := ""
+		if p.Threshold == cal.Threshold {
+			mark = "<- fastest"
+		}
+		fmt.Printf("%9d  %12v  %-40s %s\n", p.Threshold, p.Time.Round(time.Microsecond), bar, mark)
+	}
+
+	if *out != "" {
+		if err := cal.Save(*out); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("\nthreshold %d saved to %s\n", cal.Threshold, *out)
+	}
+}
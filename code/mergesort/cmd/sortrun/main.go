@@ -0,0 +1,105 @@
+// Sortrun runs one mergesort experiment described entirely by its flags, so
+// a run can be repeated from the command line it prints.
+//
+// go run ./cmd/sortrun -version v1 -n 10 -iters 10000 -trace v1.trace
+// GOGC=50 go run ./cmd/sortrun -version v3 -n 1000000 -dist nearly-sorted -seed 7
+// go run ./cmd/sortrun -version v3 -iters 10 -input ints.dset
+// go run ./cmd/sortrun -version v2 -mergemode pingpong -cpuprofile cpu.out -memprofile mem.out
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+
+	"github.com/sathishvj/optimizing-go-programs/code/dataset"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort/experiment"
+)
+
+func main() {
+	w := experiment.Workload{Strategy: mergesort.V1, Size: 10, Iterations: 10000, Dist: dataset.Uniform, Seed: 1}
+	w.Flags(flag.CommandLine)
+
+	var (
+		calibration = flag.String("calibration", "", "threshold calibration file written by cmd/calibrate")
+		traceOut    = flag.String("trace", "", "write an execution trace to this file")
+		cpuOut      = flag.String("cpuprofile", "", "write a CPU profile to this file")
+		memOut      = flag.String("memprofile", "", "write a heap profile to this file")
+	)
+	flag.Parse()
+
+	if *calibration != "" {
+		if err := mergesort.UseCalibration(*calibration); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *traceOut != "" {
+		f := create(*traceOut)
+		defer f.Close()
+		if err := trace.Start(f); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *cpuOut != "" {
+		f := create(*cpuOut)
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	res, err := w.Run()
+
+	// stop in reverse order before anything is reported
+	if *cpuOut != "" {
+		pprof.StopCPUProfile()
+	}
+	if *traceOut != "" {
+		trace.Stop()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *memOut != "" {
+		f := create(*memOut)
+		defer f.Close()
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// every flag, defaults included, so the line reproduces the run
+	cmd := []string{fmt.Sprintf("GOMAXPROCS=%d", runtime.GOMAXPROCS(0))}
+	for _, env := range []string{"GOGC", "GOMEMLIMIT", "GODEBUG"} {
+		if v, ok := os.LookupEnv(env); ok {
+			cmd = append(cmd, env+"="+v)
+		}
+	}
+	cmd = append(cmd, "sortrun")
+	flag.VisitAll(func(f *flag.Flag) {
+		cmd = append(cmd, fmt.Sprintf("-%s=%s", f.Name, f.Value))
+	})
+	fmt.Println(strings.Join(cmd, " "))
+	fmt.Printf("elapsed:     %v\n", res.Elapsed)
+	fmt.Printf("sort time:   %v\n", res.SortTime)
+	fmt.Printf("gc cycles:   %d (pause %v)\n", res.NumGC, res.PauseTotal)
+	fmt.Printf("allocated:   %s in %d allocations\n", experiment.Bytes(res.TotalAlloc), res.Mallocs)
+	fmt.Printf("peak heap:   %s (goal %s)\n", experiment.Bytes(res.PeakHeap), experiment.Bytes(res.HeapGoal))
+}
+
+func create(path string) *os.File {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return f
+}
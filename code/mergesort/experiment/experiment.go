@@ -0,0 +1,133 @@
+// Package experiment runs a mergesort workload and measures what the
+// gomaxprocs and gogc experiments look at: time, GC cycles and allocation.
+package experiment
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/sathishvj/optimizing-go-programs/code/dataset"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort"
+)
+
+// Workload describes one reproducible run: Iterations sorts of a freshly
+// generated slice of Size elements each, or of a copy of the dataset file
+// Input when it is set.
+type Workload struct {
+	Strategy   mergesort.Strategy
+	MergeMode  mergesort.MergeMode
+	Size       int
+	Iterations int
+	Dist       dataset.Dist
+	Seed       uint64
+	Input      string
+
+	// SampleInterval is how often the heap goal and heap size are sampled
+	// for their peaks. 0 only samples at the start and end of the run.
+	SampleInterval time.Duration
+}
+
+// Flags registers the workload's fields as flags on fs, with the current
+// values as defaults.
+func (w *Workload) Flags(fs *flag.FlagSet) {
+	fs.TextVar(&w.Strategy, "version", w.Strategy, "mergesort version: seq, v1, v2, v3, v4")
+	w.SweepFlags(fs)
+}
+
+// SweepFlags registers the flags of Flags except -version, for commands
+// that run the workload with several strategies of their own choosing.
+func (w *Workload) SweepFlags(fs *flag.FlagSet) {
+	fs.TextVar(&w.MergeMode, "mergemode", w.MergeMode, "merge mode: alloc, pingpong")
+	fs.IntVar(&w.Size, "n", w.Size, "elements per input slice")
+	fs.IntVar(&w.Iterations, "iters", w.Iterations, "number of slices to generate and sort")
+	fs.TextVar(&w.Dist, "dist", w.Dist, "data distribution: uniform, sorted, reverse-sorted, nearly-sorted, few-unique, zipf")
+	fs.Uint64Var(&w.Seed, "seed", w.Seed, "seed for the input data")
+	fs.StringVar(&w.Input, "input", w.Input, "sort copies of this dataset file (see dataset/cmd/gendata) instead of generated data")
+}
+
+// Result is what a Workload run measured.
+type Result struct {
+	// Elapsed is the wall time of the whole run, SortTime the part of it
+	// spent inside mergesort.
+	Elapsed  time.Duration
+	SortTime time.Duration
+
+	// Deltas of the runtime metrics over the run. PauseTotal is
+	// approximated from the pause histogram.
+	NumGC      uint64
+	PauseTotal time.Duration
+	TotalAlloc uint64
+	Mallocs    uint64
+
+	// GCCPUFraction is the share of the available CPU time, GOMAXPROCS
+	// times wall time, that the runtime estimates went to GC work.
+	GCCPUFraction float64
+
+	// Highest heap goal, heap size and total runtime memory (what
+	// GOMEMLIMIT limits) sampled during the run.
+	HeapGoal   uint64
+	PeakHeap   uint64
+	PeakMemory uint64
+}
+
+// Run generates and sorts the workload's inputs. As in the original gogc
+// experiment each iteration allocates its own input, so the generated data
+// is part of the garbage being measured.
+func (w Workload) Run() (Result, error) {
+	var res Result
+	g := dataset.New(w.Seed)
+	var input []int
+	if w.Input != "" {
+		var err error
+		if input, err = dataset.LoadInts(w.Input); err != nil {
+			return res, err
+		}
+	}
+	opts := []mergesort.Option{
+		mergesort.WithStrategy(w.Strategy),
+		mergesort.WithMergeMode(w.MergeMode),
+		// Only has an effect while an execution trace is recorded, as
+		// sortrun -trace does.
+		mergesort.WithTracing(true),
+	}
+
+	runtime.GC()
+	before := readMetrics(runMetrics)
+	peaks := startPeakSampler(w.SampleInterval)
+	start := time.Now()
+
+	for i := 0; i < w.Iterations; i++ {
+		var s []int
+		if input != nil {
+			s = slices.Clone(input)
+		} else {
+			s = g.Ints(w.Dist, w.Size)
+		}
+
+		t := time.Now()
+		mergesort.Sort(s, opts...)
+		res.SortTime += time.Since(t)
+	}
+
+	res.Elapsed = time.Since(start)
+	peaks.Stop(&res)
+	res.delta(before, readMetrics(runMetrics))
+	return res, nil
+}
+
+// Bytes formats n with a binary unit suffix.
+func Bytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
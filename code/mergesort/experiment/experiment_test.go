@@ -0,0 +1,186 @@
+package experiment
+
+import (
+	"flag"
+	"io"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sathishvj/optimizing-go-programs/code/dataset"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort"
+)
+
+func Test_Workload_Run(t *testing.T) {
+	w := Workload{Strategy: mergesort.V3, Size: 100, Iterations: 10, Dist: dataset.Uniform, Seed: 1}
+	res, err := w.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Elapsed < res.SortTime || res.TotalAlloc == 0 || res.Mallocs == 0 {
+		t.Errorf("unexpected result %+v", res)
+	}
+
+	path := filepath.Join(t.TempDir(), "ints.dset")
+	if err := dataset.SaveInts(path, dataset.New(9).Ints(dataset.Sorted, 500)); err != nil {
+		t.Fatal(err)
+	}
+	w.Input = path
+	if res, err = w.Run(); err != nil || res.TotalAlloc < 10*500*8 {
+		t.Errorf("expected 10 copies of the input to be sorted, got %+v, %v", res, err)
+	}
+
+	w.Input = filepath.Join(t.TempDir(), "missing.dset")
+	if _, err := w.Run(); err == nil {
+		t.Errorf("expected an error for a missing input file")
+	}
+}
+
+func Test_ParseGCPercents(t *testing.T) {
+	got, err := ParseGCPercents("off, 50,100")
+	if err != nil || !slices.Equal(got, []int{GCOff, 50, 100}) {
+		t.Errorf("got %v, %v", got, err)
+	}
+	for _, bad := range []string{"", "on", "-5", "50,,100"} {
+		if _, err := ParseGCPercents(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func Test_GCSweep(t *testing.T) {
+	orig := debug.SetGCPercent(-1) // whatever GOGC the test runs with
+	debug.SetGCPercent(orig)
+
+	w := Workload{Strategy: mergesort.V2, Size: 1000, Iterations: 20, Dist: dataset.Uniform, Seed: 1}
+	runs, err := GCSweep(w, GCSettings([]int{GCOff, 10}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].NumGC != 0 || runs[1].NumGC == 0 {
+		t.Errorf("expected no GC with GOGC=off and some with GOGC=10, got %+v", runs)
+	}
+	if got := debug.SetGCPercent(orig); got != orig {
+		t.Errorf("GOGC was not restored: expected %d but got %d", orig, got)
+	}
+
+	var md, csv strings.Builder
+	table := GCTable(runs)
+	table.Write(&md, "md")
+	table.Write(&csv, "csv")
+	if !strings.HasPrefix(md.String(), "| GOGC |") || !strings.Contains(md.String(), "| off |") {
+		t.Errorf("unexpected markdown:\n%s", md.String())
+	}
+	if lines := strings.Split(strings.TrimSpace(csv.String()), "\n"); len(lines) != 3 {
+		t.Errorf("expected a header and 2 rows but got:\n%s", csv.String())
+	}
+	if err := table.Write(&md, "html"); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func Test_ParseMemoryLimits(t *testing.T) {
+	got, err := ParseMemoryLimits("off, 512, 100B,16KiB,8MiB,2GiB,1TiB")
+	exp := []int64{NoLimit, 512, 100, 16 << 10, 8 << 20, 2 << 30, 1 << 40}
+	if err != nil || !slices.Equal(got, exp) {
+		t.Errorf("got %v, %v", got, err)
+	}
+	for _, bad := range []string{"", "8MB", "-1MiB", "0", "99999999TiB"} {
+		if _, err := ParseMemoryLimits(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func Test_GCSweep_MemoryLimit(t *testing.T) {
+	settings := GCSettings([]int{GCOff}, []int64{NoLimit, 8 << 20})
+	if len(settings) != 2 || settings[1] != (GCSetting{GCOff, 8 << 20}) {
+		t.Fatalf("unexpected settings %v", settings)
+	}
+
+	orig := debug.SetMemoryLimit(-1) // whatever GOMEMLIMIT the test runs with
+
+	w := Workload{Strategy: mergesort.V2, Size: 10000, Iterations: 100, Dist: dataset.Uniform, Seed: 1}
+	runs, err := GCSweep(w, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs[0].NumGC != 0 || runs[1].NumGC == 0 {
+		t.Errorf("expected the memory limit alone to trigger GC, got %+v", runs)
+	}
+	if runs[1].GCCPUFraction <= 0 || runs[1].PeakMemory == 0 {
+		t.Errorf("expected GC CPU and peak memory to be measured, got %+v", runs[1])
+	}
+	if got := debug.SetMemoryLimit(-1); got != orig {
+		t.Errorf("the memory limit was not restored: expected %d but got %d", orig, got)
+	}
+
+	var md strings.Builder
+	GCTable(runs).WriteMarkdown(&md)
+	if !strings.Contains(md.String(), "| off | 8.0MiB |") {
+		t.Errorf("unexpected markdown:\n%s", md.String())
+	}
+}
+
+func Test_ParseProcs(t *testing.T) {
+	got, err := ParseProcs("1-3, 8")
+	if err != nil || !slices.Equal(got, []int{1, 2, 3, 8}) {
+		t.Errorf("got %v, %v", got, err)
+	}
+	got, err = ParseProcs("4,1-2,2")
+	if err != nil || !slices.Equal(got, []int{1, 2, 4}) {
+		t.Errorf("expected the values sorted and deduplicated but got %v, %v", got, err)
+	}
+	if got, _ := ParseProcs(""); len(got) != runtime.NumCPU() {
+		t.Errorf("expected 1 to NumCPU but got %v", got)
+	}
+	for _, bad := range []string{"0", "4-2", "x", "1,"} {
+		if _, err := ParseProcs(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func Test_ProcsSweep(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	runtime.GOMAXPROCS(3)
+
+	w := Workload{Size: 1 << 12, Iterations: 1, Dist: dataset.Uniform, Seed: 1}
+	runs, err := ProcsSweep(w, []mergesort.Strategy{mergesort.V3, mergesort.V4}, []int{2, 1, 2}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 4 {
+		t.Fatalf("expected 4 runs but got %d", len(runs))
+	}
+	if runs[0].Procs != 1 || runs[0].Speedup != 1 || runs[0].Efficiency != 1 || runs[1].Procs != 2 || runs[2].Strategy != mergesort.V4 {
+		t.Errorf("unexpected runs %+v", runs)
+	}
+	if runtime.GOMAXPROCS(0) != 3 {
+		t.Errorf("GOMAXPROCS was not restored")
+	}
+
+	var svg strings.Builder
+	if err := WriteSpeedupSVG(&svg, runs); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(svg.String(), "<polyline"); n != 2 {
+		t.Errorf("expected a line per version but got %d", n)
+	}
+}
+
+func Test_SweepFlags(t *testing.T) {
+	var w Workload
+	fs := flag.NewFlagSet("sweep", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	w.SweepFlags(fs)
+	if err := fs.Parse([]string{"-version", "v1"}); err == nil {
+		t.Errorf("expected -version to be rejected")
+	}
+	if err := fs.Parse([]string{"-n", "10"}); err != nil || w.Size != 10 {
+		t.Errorf("expected -n to set the size but got %d, %v", w.Size, err)
+	}
+}
@@ -0,0 +1,148 @@
+package experiment
+
+import (
+	"fmt"
+	"math"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GCOff is the GOGC value that turns the collector off.
+const GCOff = -1
+
+// NoLimit is the memory limit the runtime uses when GOMEMLIMIT is not set.
+const NoLimit = math.MaxInt64
+
+// ParseGCPercents parses a comma separated list of GOGC values such as
+// "off,50,100,200".
+func ParseGCPercents(list string) ([]int, error) {
+	var percents []int
+	for _, f := range strings.Split(list, ",") {
+		f = strings.TrimSpace(f)
+		if f == "off" {
+			percents = append(percents, GCOff)
+			continue
+		}
+		p, err := strconv.Atoi(f)
+		if err != nil || p < 0 {
+			return nil, fmt.Errorf("experiment: invalid GOGC value %q", f)
+		}
+		percents = append(percents, p)
+	}
+	return percents, nil
+}
+
+// ParseMemoryLimits parses a comma separated list of memory limits in the
+// GOMEMLIMIT format, a number of bytes with an optional B, KiB, MiB, GiB or
+// TiB suffix, such as "16MiB,64MiB,1GiB". "off" stands for NoLimit.
+func ParseMemoryLimits(list string) ([]int64, error) {
+	var limits []int64
+	for _, f := range strings.Split(list, ",") {
+		f = strings.TrimSpace(f)
+		if f == "off" {
+			limits = append(limits, NoLimit)
+			continue
+		}
+
+		num, mult := f, int64(1)
+		for i, suffix := range []string{"TiB", "GiB", "MiB", "KiB", "B"} {
+			if n, ok := strings.CutSuffix(f, suffix); ok {
+				num, mult = n, 1<<(10*(4-i))
+				break
+			}
+		}
+		n, err := strconv.ParseInt(num, 10, 64)
+		if err != nil || n <= 0 || n > NoLimit/mult {
+			return nil, fmt.Errorf("experiment: invalid memory limit %q", f)
+		}
+		limits = append(limits, n*mult)
+	}
+	return limits, nil
+}
+
+func gcPercentString(p int) string {
+	if p < 0 {
+		return "off"
+	}
+	return strconv.Itoa(p)
+}
+
+// GCSetting is one combination of the two GC knobs.
+type GCSetting struct {
+	Percent     int   // GOGC, or GCOff
+	MemoryLimit int64 // GOMEMLIMIT in bytes, or NoLimit
+}
+
+// GCSettings returns every combination of percents and limits. With no
+// limits it is the plain GOGC sweep.
+func GCSettings(percents []int, limits []int64) []GCSetting {
+	if len(limits) == 0 {
+		limits = []int64{NoLimit}
+	}
+	var settings []GCSetting
+	for _, p := range percents {
+		for _, l := range limits {
+			settings = append(settings, GCSetting{p, l})
+		}
+	}
+	return settings
+}
+
+// GCRun is the result of the workload under one GC setting.
+type GCRun struct {
+	GCSetting
+	Result
+}
+
+// GCSweep runs w once for every setting, applied in-process with
+// debug.SetGCPercent and debug.SetMemoryLimit, and restores the original
+// settings afterwards.
+func GCSweep(w Workload, settings []GCSetting) ([]GCRun, error) {
+	origPercent := debug.SetGCPercent(100)
+	origLimit := debug.SetMemoryLimit(-1)
+	defer func() {
+		debug.SetGCPercent(origPercent)
+		debug.SetMemoryLimit(origLimit)
+	}()
+
+	var runs []GCRun
+	for _, gs := range settings {
+		debug.SetGCPercent(gs.Percent)
+		debug.SetMemoryLimit(gs.MemoryLimit)
+		res, err := w.Run()
+		if err != nil {
+			return runs, err
+		}
+		runs = append(runs, GCRun{gs, res})
+	}
+	return runs, nil
+}
+
+// heapGoal formats a heap goal, which is effectively infinite while the
+// collector is off and there is no memory limit.
+func heapGoal(n uint64) string {
+	if n >= math.MaxInt64/2 {
+		return "none"
+	}
+	return Bytes(n)
+}
+
+// GCTable reports the runs of a GCSweep. "of limit" is the peak runtime
+// memory as a share of the memory limit.
+func GCTable(runs []GCRun) *Table {
+	t := &Table{Header: []string{"GOGC", "GOMEMLIMIT", "elapsed", "gc cycles", "gc pause", "gc cpu",
+		"heap goal", "peak heap", "peak mem", "of limit", "allocated"}}
+	for _, r := range runs {
+		limit, ofLimit := "off", "-"
+		if r.MemoryLimit != NoLimit {
+			limit = Bytes(uint64(r.MemoryLimit))
+			ofLimit = fmt.Sprintf("%.0f%%", 100*float64(r.PeakMemory)/float64(r.MemoryLimit))
+		}
+		t.Add(gcPercentString(r.Percent), limit, r.Elapsed.Round(time.Microsecond), r.NumGC,
+			r.PauseTotal.Round(time.Microsecond), fmt.Sprintf("%.1f%%", 100*r.GCCPUFraction),
+			heapGoal(r.HeapGoal), Bytes(r.PeakHeap), Bytes(r.PeakMemory), ofLimit, Bytes(r.TotalAlloc))
+	}
+	return t
+}
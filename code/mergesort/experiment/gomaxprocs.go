@@ -0,0 +1,114 @@
+package experiment
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort"
+)
+
+// ParseProcs parses a comma separated list of GOMAXPROCS values, where an
+// item may also be a range such as "1-8". An empty list means 1 to
+// runtime.NumCPU(). The values are returned in ascending order, each once.
+func ParseProcs(list string) ([]int, error) {
+	if list == "" {
+		list = "1-" + strconv.Itoa(runtime.NumCPU())
+	}
+
+	var procs []int
+	for _, f := range strings.Split(list, ",") {
+		lo, hi, isRange := strings.Cut(strings.TrimSpace(f), "-")
+		if !isRange {
+			hi = lo
+		}
+		from, err1 := strconv.Atoi(lo)
+		to, err2 := strconv.Atoi(hi)
+		if err1 != nil || err2 != nil || from < 1 || to < from {
+			return nil, fmt.Errorf("experiment: invalid GOMAXPROCS value %q", f)
+		}
+		for n := from; n <= to; n++ {
+			procs = append(procs, n)
+		}
+	}
+	slices.Sort(procs)
+	return slices.Compact(procs), nil
+}
+
+// ParseStrategies parses a comma separated list of mergesort versions.
+func ParseStrategies(list string) ([]mergesort.Strategy, error) {
+	var strategies []mergesort.Strategy
+	for _, f := range strings.Split(list, ",") {
+		st, err := mergesort.ParseStrategy(strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+		strategies = append(strategies, st)
+	}
+	return strategies, nil
+}
+
+// ProcsRun is one version under one GOMAXPROCS setting.
+type ProcsRun struct {
+	Strategy mergesort.Strategy
+	Procs    int
+
+	// Median of the repeated sort times.
+	Median time.Duration
+	// Speedup is the median at the smallest GOMAXPROCS of the sweep divided
+	// by this median, and Efficiency is Speedup per P relative to that
+	// smallest setting.
+	Speedup    float64
+	Efficiency float64
+}
+
+// ProcsSweep runs w for every strategy under every GOMAXPROCS value,
+// repeat times each, and restores GOMAXPROCS afterwards. Only the time spent
+// sorting counts, not generating the input. The values of procs are run
+// in ascending order, each once, and the smallest is the baseline for the
+// speedup.
+func ProcsSweep(w Workload, strategies []mergesort.Strategy, procs []int, repeat int) ([]ProcsRun, error) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	procs = slices.Compact(slices.Sorted(slices.Values(procs)))
+
+	var runs []ProcsRun
+	for _, st := range strategies {
+		w.Strategy = st
+		var base ProcsRun
+		for i, n := range procs {
+			runtime.GOMAXPROCS(n)
+
+			times := make([]time.Duration, max(repeat, 1))
+			for j := range times {
+				res, err := w.Run()
+				if err != nil {
+					return runs, err
+				}
+				times[j] = res.SortTime
+			}
+			slices.Sort(times)
+
+			run := ProcsRun{Strategy: st, Procs: n, Median: times[len(times)/2]}
+			if i == 0 {
+				base = run
+			}
+			run.Speedup = float64(base.Median) / float64(run.Median)
+			run.Efficiency = run.Speedup * float64(base.Procs) / float64(n)
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+// ProcsTable reports the runs of a ProcsSweep.
+func ProcsTable(runs []ProcsRun) *Table {
+	t := &Table{Header: []string{"version", "GOMAXPROCS", "median", "speedup", "efficiency"}}
+	for _, r := range runs {
+		t.Add(r.Strategy, r.Procs, r.Median.Round(time.Microsecond),
+			fmt.Sprintf("%.2fx", r.Speedup), fmt.Sprintf("%.0f%%", 100*r.Efficiency))
+	}
+	return t
+}
@@ -0,0 +1,142 @@
+package experiment
+
+import (
+	"math"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// Runtime metrics read at the start and end of every run, in this order.
+var runMetrics = []string{
+	"/gc/cycles/total:gc-cycles",
+	"/sched/pauses/total/gc:seconds",
+	"/gc/heap/allocs:bytes",
+	"/gc/heap/allocs:objects",
+	"/gc/heap/tiny/allocs:objects",
+	"/cpu/classes/gc/total:cpu-seconds",
+	"/cpu/classes/total:cpu-seconds",
+}
+
+const (
+	mCycles = iota
+	mPauses
+	mAllocBytes
+	mAllocObjects
+	mTinyObjects
+	mGCCPU
+	mTotalCPU
+)
+
+// Runtime metrics tracked for their highest value during a run.
+var peakMetrics = []string{
+	"/gc/heap/goal:bytes",
+	"/memory/classes/heap/objects:bytes",
+	"/memory/classes/total:bytes",
+	"/memory/classes/heap/released:bytes",
+}
+
+const (
+	mHeapGoal = iota
+	mHeapObjects
+	mMemTotal
+	mMemReleased
+)
+
+func readMetrics(names []string) []metrics.Sample {
+	s := make([]metrics.Sample, len(names))
+	for i, name := range names {
+		s[i].Name = name
+	}
+	metrics.Read(s)
+	return s
+}
+
+// histogramSum approximates the sum of the values in h, counting each
+// sample at the middle of its bucket.
+func histogramSum(h *metrics.Float64Histogram) float64 {
+	var sum float64
+	for i, n := range h.Counts {
+		if n == 0 {
+			continue
+		}
+		lo, hi := h.Buckets[i], h.Buckets[i+1]
+		switch {
+		case math.IsInf(lo, -1):
+			lo = 0
+		case math.IsInf(hi, 1):
+			hi = lo
+		}
+		sum += float64(n) * (lo + hi) / 2
+	}
+	return sum
+}
+
+// delta fills res with the difference between two reads of runMetrics.
+func (res *Result) delta(before, after []metrics.Sample) {
+	res.NumGC = after[mCycles].Value.Uint64() - before[mCycles].Value.Uint64()
+	pause := histogramSum(after[mPauses].Value.Float64Histogram()) -
+		histogramSum(before[mPauses].Value.Float64Histogram())
+	res.PauseTotal = time.Duration(pause * float64(time.Second))
+	res.TotalAlloc = after[mAllocBytes].Value.Uint64() - before[mAllocBytes].Value.Uint64()
+	res.Mallocs = after[mAllocObjects].Value.Uint64() - before[mAllocObjects].Value.Uint64() +
+		after[mTinyObjects].Value.Uint64() - before[mTinyObjects].Value.Uint64()
+	if cpu := after[mTotalCPU].Value.Float64() - before[mTotalCPU].Value.Float64(); cpu > 0 {
+		res.GCCPUFraction = (after[mGCCPU].Value.Float64() - before[mGCCPU].Value.Float64()) / cpu
+	}
+}
+
+// peakSampler reads peakMetrics on a background goroutine and keeps the
+// highest value seen. Peaks between two samples are missed, so the interval
+// should be short compared with a GC cycle.
+type peakSampler struct {
+	stop chan struct{}
+	wg   sync.WaitGroup
+	peak []uint64
+
+	// peakMemory is the highest total minus released memory, the amount
+	// the memory limit applies to.
+	peakMemory uint64
+}
+
+func startPeakSampler(interval time.Duration) *peakSampler {
+	p := &peakSampler{stop: make(chan struct{}), peak: make([]uint64, len(peakMetrics))}
+	p.sample()
+	if interval <= 0 {
+		return p
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-t.C:
+				p.sample()
+			}
+		}
+	}()
+	return p
+}
+
+func (p *peakSampler) sample() {
+	s := readMetrics(peakMetrics)
+	for i := range s {
+		p.peak[i] = max(p.peak[i], s[i].Value.Uint64())
+	}
+	p.peakMemory = max(p.peakMemory, s[mMemTotal].Value.Uint64()-s[mMemReleased].Value.Uint64())
+}
+
+// Stop ends sampling, takes a last sample and stores the peaks in res.
+func (p *peakSampler) Stop(res *Result) {
+	close(p.stop)
+	p.wg.Wait()
+	p.sample()
+	res.HeapGoal = p.peak[mHeapGoal]
+	res.PeakHeap = p.peak[mHeapObjects]
+	res.PeakMemory = p.peakMemory
+}
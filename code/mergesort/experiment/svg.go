@@ -0,0 +1,92 @@
+package experiment
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort"
+)
+
+var lineColors = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b"}
+
+// WriteSpeedupSVG draws the speedup of every version against GOMAXPROCS as
+// a line chart, with the ideal linear speedup dashed for reference.
+func WriteSpeedupSVG(w io.Writer, runs []ProcsRun) error {
+	if len(runs) == 0 {
+		return fmt.Errorf("experiment: no runs to draw")
+	}
+
+	const (
+		width, height = 640, 400
+		left, right   = 60, 120
+		top, bottom   = 30, 50
+	)
+
+	var order []mergesort.Strategy
+	lines := map[mergesort.Strategy][]ProcsRun{}
+	minProcs, maxProcs, maxSpeedup := runs[0].Procs, runs[0].Procs, 1.0
+	for _, r := range runs {
+		if _, ok := lines[r.Strategy]; !ok {
+			order = append(order, r.Strategy)
+		}
+		lines[r.Strategy] = append(lines[r.Strategy], r)
+		minProcs = min(minProcs, r.Procs)
+		maxProcs = max(maxProcs, r.Procs)
+		maxSpeedup = max(maxSpeedup, r.Speedup)
+	}
+	ideal := float64(maxProcs) / float64(minProcs)
+	yMax := math.Ceil(max(maxSpeedup, ideal))
+
+	x := func(procs int) float64 {
+		if maxProcs == minProcs {
+			return left
+		}
+		return left + float64(procs-minProcs)/float64(maxProcs-minProcs)*(width-left-right)
+	}
+	y := func(speedup float64) float64 {
+		return height - bottom - speedup/yMax*(height-top-bottom)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n", width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	fmt.Fprintf(&b, `<text x="%d" y="18" font-size="14">mergesort speedup vs GOMAXPROCS</text>`+"\n", left)
+
+	// axes and ticks
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black"/>`+"\n", left, height-bottom, width-right, height-bottom)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black"/>`+"\n", left, top, left, height-bottom)
+	xStep := max(1, (maxProcs-minProcs)/10)
+	for p := minProcs; p <= maxProcs; p += xStep {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%d</text>`+"\n", x(p), height-bottom+16, p)
+	}
+	yStep := max(1, math.Ceil(yMax/8))
+	for s := 0.0; s <= yMax; s += yStep {
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#eee"/>`+"\n", left, y(s), width-right, y(s))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">%gx</text>`+"\n", left-6, y(s)+4, s)
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">GOMAXPROCS</text>`+"\n", (width-right+left)/2, height-12)
+
+	fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="gray" stroke-dasharray="4 4"/>`+"\n",
+		x(minProcs), y(1), x(maxProcs), y(ideal))
+
+	for i, st := range order {
+		color := lineColors[i%len(lineColors)]
+		var points []string
+		for _, r := range lines[st] {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x(r.Procs), y(r.Speedup)))
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`+"\n", color, strings.Join(points, " "))
+		ly := top + 20*i
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="2"/>`+"\n", width-right+15, ly, width-right+35, ly, color)
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`+"\n", width-right+40, ly+4, st)
+	}
+	ly := top + 20*len(order)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="gray" stroke-dasharray="4 4"/>`+"\n", width-right+15, ly, width-right+35, ly)
+	fmt.Fprintf(&b, `<text x="%d" y="%d">ideal</text>`+"\n", width-right+40, ly+4)
+
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,72 @@
+package experiment
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Table is a report with one row per run of a sweep.
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Add appends a row, formatting each value with fmt.Sprint.
+func (t *Table) Add(values ...any) {
+	row := make([]string, len(values))
+	for i, v := range values {
+		row[i] = fmt.Sprint(v)
+	}
+	t.Rows = append(t.Rows, row)
+}
+
+// Write writes the table in the given format: text, md or csv.
+func (t *Table) Write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		return t.WriteText(w)
+	case "md":
+		return t.WriteMarkdown(w)
+	case "csv":
+		return t.WriteCSV(w)
+	}
+	return fmt.Errorf("experiment: unknown table format %q", format)
+}
+
+// WriteText writes the table as aligned columns.
+func (t *Table) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.Header, "\t"))
+	for _, row := range t.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// WriteMarkdown writes the table as a GitHub flavoured Markdown table.
+func (t *Table) WriteMarkdown(w io.Writer) error {
+	line := func(cells []string) {
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+	line(t.Header)
+	sep := make([]string, len(t.Header))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	line(sep)
+	for _, row := range t.Rows {
+		line(row)
+	}
+	return nil
+}
+
+// WriteCSV writes the table as CSV with a header line.
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(t.Header)
+	cw.WriteAll(t.Rows)
+	return cw.Error()
+}
@@ -0,0 +1,85 @@
+package mergesort
+
+// merge merges the sorted runs s[:middle] and s[middle:] in place, using a
+// freshly allocated copy of s as the source.
+func merge[T any](s []T, middle int, cmp func(a, b T) int) {
+	helper := make([]T, len(s))
+	copy(helper, s)
+
+	helperLeft := 0
+	helperRight := middle
+	current := 0
+	high := len(s) - 1
+
+	for helperLeft <= middle-1 && helperRight <= high {
+		if cmp(helper[helperLeft], helper[helperRight]) <= 0 {
+			s[current] = helper[helperLeft]
+			helperLeft++
+		} else {
+			s[current] = helper[helperRight]
+			helperRight++
+		}
+		current++
+	}
+
+	for helperLeft <= middle-1 {
+		s[current] = helper[helperLeft]
+		current++
+		helperLeft++
+	}
+}
+
+// mergeInto merges the sorted runs left and right into dst, which must be
+// len(left)+len(right) long and must not overlap either run.
+func mergeInto[T any](dst, left, right []T, cmp func(a, b T) int) {
+	l, r, current := 0, 0, 0
+
+	for l < len(left) && r < len(right) {
+		if cmp(left[l], right[r]) <= 0 {
+			dst[current] = left[l]
+			l++
+		} else {
+			dst[current] = right[r]
+			r++
+		}
+		current++
+	}
+
+	current += copy(dst[current:], left[l:])
+	copy(dst[current:], right[r:])
+}
+
+// span is the part of the input a recursion step sorts, together with its
+// scratch space.
+//
+// In the Allocating mode scratch is nil. In the PingPong mode scratch holds
+// the same elements as s on entry. The halves are sorted into scratch, with
+// the matching halves of s as their scratch, and are then merged back into
+// s, so source and destination swap at every level. Sibling spans cover
+// disjoint ranges of both slices, which is what lets the v2/v3 goroutines
+// share the one buffer without locking.
+//
+// depth counts the splits from the whole input down to the span.
+type span[T any] struct {
+	s, scratch []T
+	depth      int
+}
+
+func (p span[T]) split() (left, right span[T], middle int) {
+	middle = len(p.s) / 2
+	if p.scratch == nil {
+		return span[T]{s: p.s[:middle], depth: p.depth + 1}, span[T]{s: p.s[middle:], depth: p.depth + 1}, middle
+	}
+	left = span[T]{s: p.scratch[:middle], scratch: p.s[:middle], depth: p.depth + 1}
+	right = span[T]{s: p.scratch[middle:], scratch: p.s[middle:], depth: p.depth + 1}
+	return left, right, middle
+}
+
+// merge merges the two sorted halves produced by p.split into p.s.
+func (x *sorter[T]) merge(p span[T], middle int) {
+	if p.scratch == nil {
+		merge(p.s, middle, x.cmp)
+		return
+	}
+	mergeInto(p.s, p.scratch[:middle], p.scratch[middle:], x.cmp)
+}
@@ -0,0 +1,425 @@
+// ref: https://hackernoon.com/parallel-merge-sort-in-go-fe14c1bc006
+
+// Package mergesort is the parallel merge sort shared by the gomaxprocs, gogc
+// and tracing experiments.
+//
+//	mergesort.Sort(s)                                       // v3, default threshold
+//	mergesort.Sort(s, mergesort.WithStrategy(mergesort.V1)) // goroutine per split
+//	mergesort.SortFunc(people, byAge, mergesort.WithMaxGoroutines(8))
+package mergesort
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/bits"
+	"runtime"
+	"runtime/trace"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Strategy selects how the recursion is spread over goroutines.
+type Strategy int
+
+const (
+	// Sequential never starts a goroutine.
+	Sequential Strategy = iota
+	// V1 starts a goroutine for each half at every split, all the way down.
+	V1
+	// V2 starts a goroutine for each half until the slice is at or below
+	// the threshold, then sorts sequentially.
+	V2
+	// V3 starts a goroutine for the first half and sorts the second half on
+	// the calling goroutine, until the slice is at or below the threshold.
+	V3
+	// V4 works like V3 but is bounded by the processors available instead
+	// of by the threshold: it splits onto new goroutines only until there is
+	// one running per P, ceil(log2(GOMAXPROCS)) levels deep, and sorts
+	// sequentially below that.
+	V4
+)
+
+var strategyNames = []string{
+	Sequential: "seq",
+	V1:         "v1",
+	V2:         "v2",
+	V3:         "v3",
+	V4:         "v4",
+}
+
+func (st Strategy) String() string {
+	if st >= 0 && int(st) < len(strategyNames) {
+		return strategyNames[st]
+	}
+	return fmt.Sprintf("Strategy(%d)", int(st))
+}
+
+// ParseStrategy returns the strategy with the given name, as used on the
+// command line of the experiments: seq, v1, v2, v3 or v4.
+func ParseStrategy(name string) (Strategy, error) {
+	for st, n := range strategyNames {
+		if n == name {
+			return Strategy(st), nil
+		}
+	}
+	return 0, fmt.Errorf("mergesort: unknown strategy %q", name)
+}
+
+// MarshalText implements encoding.TextMarshaler, so a Strategy can be used
+// with flag.TextVar.
+func (st Strategy) MarshalText() ([]byte, error) {
+	return []byte(st.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParseStrategy.
+func (st *Strategy) UnmarshalText(text []byte) error {
+	parsed, err := ParseStrategy(string(text))
+	if err != nil {
+		return err
+	}
+	*st = parsed
+	return nil
+}
+
+// DefaultThreshold is the slice length at or below which V2 and V3 stop
+// starting goroutines, until UseCalibration loads a measured one for the
+// strategy.
+const DefaultThreshold = 1 << 11
+
+// MergeMode selects where merge gets its auxiliary memory from.
+type MergeMode int
+
+const (
+	// Allocating allocates and fills a helper slice on every merge, so a
+	// sort produces O(n log n) bytes of garbage.
+	Allocating MergeMode = iota
+	// PingPong allocates a single scratch buffer per Sort call and
+	// alternates source and destination between recursion levels.
+	PingPong
+)
+
+var mergeModeNames = []string{
+	Allocating: "alloc",
+	PingPong:   "pingpong",
+}
+
+func (m MergeMode) String() string {
+	if m >= 0 && int(m) < len(mergeModeNames) {
+		return mergeModeNames[m]
+	}
+	return fmt.Sprintf("MergeMode(%d)", int(m))
+}
+
+// ParseMergeMode returns the merge mode with the given name: alloc or
+// pingpong.
+func ParseMergeMode(name string) (MergeMode, error) {
+	for m, n := range mergeModeNames {
+		if n == name {
+			return MergeMode(m), nil
+		}
+	}
+	return 0, fmt.Errorf("mergesort: unknown merge mode %q", name)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (m MergeMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParseMergeMode.
+func (m *MergeMode) UnmarshalText(text []byte) error {
+	parsed, err := ParseMergeMode(string(text))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+type config struct {
+	strategy      Strategy
+	threshold     int
+	maxGoroutines int
+	mergeMode     MergeMode
+	trace         bool
+}
+
+// Option configures a single Sort or SortFunc call.
+type Option func(*config)
+
+// WithStrategy selects the parallel strategy. The default is V3.
+func WithStrategy(st Strategy) Option {
+	return func(c *config) { c.strategy = st }
+}
+
+// WithThreshold sets the sequential cutoff used by V2 and V3.
+// Values below 1 are ignored.
+func WithThreshold(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.threshold = n
+		}
+	}
+}
+
+// WithMaxGoroutines limits how many goroutines a single sort may have
+// running at once. Once the limit is reached the work that would have gone
+// to a new goroutine runs on the current one instead. 0 means no limit.
+func WithMaxGoroutines(n int) Option {
+	return func(c *config) { c.maxGoroutines = n }
+}
+
+// WithMergeMode selects the merge mode. The default is Allocating.
+func WithMergeMode(m MergeMode) Option {
+	return func(c *config) { c.mergeMode = m }
+}
+
+// WithTracing makes the sort annotate the execution trace, when one is being
+// recorded: a task per call, a region for every parallel split and merge
+// labelled with its depth and length, and a log entry for every decision
+// to stop splitting. See trace.go.
+func WithTracing(enabled bool) Option {
+	return func(c *config) { c.trace = enabled }
+}
+
+// Sort sorts s in ascending order.
+func Sort[T cmp.Ordered](s []T, opts ...Option) {
+	SortFunc(s, cmp.Compare[T], opts...)
+}
+
+// SortFunc sorts s in ascending order as determined by cmp, which must
+// return a negative number when a < b, a positive number when a > b and
+// zero otherwise. The sort is stable.
+func SortFunc[T any](s []T, cmp func(a, b T) int, opts ...Option) {
+	sortFunc(context.Background(), s, cmp, opts)
+}
+
+// SortContext is like Sort but gives up once ctx is done. See
+// SortFuncContext.
+func SortContext[T cmp.Ordered](ctx context.Context, s []T, opts ...Option) error {
+	return SortFuncContext(ctx, s, cmp.Compare[T], opts...)
+}
+
+// SortFuncContext is like SortFunc but gives up once ctx is done. The
+// recursion checks ctx at every split and before every merge, and starts no
+// new goroutines after it is done. SortFuncContext returns only once every
+// goroutine it started has finished: nil if s is sorted, ctx.Err() if the
+// sort was abandoned, in which case s holds its original elements in an
+// unspecified order.
+func SortFuncContext[T any](ctx context.Context, s []T, cmp func(a, b T) int, opts ...Option) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !sortFunc(ctx, s, cmp, opts) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// sortFunc sorts s, giving up once ctx is done, and reports whether it
+// finished.
+func sortFunc[T any](ctx context.Context, s []T, cmp func(a, b T) int, opts []Option) bool {
+	c := config{strategy: V3}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.threshold == 0 {
+		c.threshold = defaultThreshold(c.strategy)
+	}
+
+	x := &sorter[T]{config: c, cmp: cmp, done: ctx.Done()}
+	if c.trace && trace.IsEnabled() {
+		task := x.startTask(ctx, len(s))
+		defer task.End()
+	}
+	if c.maxGoroutines > 0 {
+		x.sem = make(chan struct{}, c.maxGoroutines)
+	}
+
+	p := span[T]{s: s}
+	if c.mergeMode == PingPong && len(s) > 1 {
+		p.scratch = slices.Clone(s)
+	}
+	x.sort(p)
+	return !x.stopped.Load()
+}
+
+// sorter carries the per-call state through the recursion.
+type sorter[T any] struct {
+	config
+	cmp  func(a, b T) int
+	sem  chan struct{}   // nil when the goroutine count is unlimited
+	done <-chan struct{} // nil when the sort cannot be cancelled
+
+	stopped atomic.Bool // set once the recursion has seen done closed
+
+	traced bool            // annotating a running execution trace
+	ctx    context.Context // the trace task, when traced
+}
+
+func (x *sorter[T]) sort(p span[T]) {
+	switch x.strategy {
+	case V1:
+		x.v1(p)
+	case V2:
+		x.v2(p)
+	case V3:
+		x.v3(p)
+	case V4:
+		x.v4(p, bits.Len(uint(runtime.GOMAXPROCS(0)-1)))
+	default:
+		x.cutoff(p, "sequential strategy")
+	}
+}
+
+// cancelled reports whether the sort has been cancelled, and records that
+// it has.
+func (x *sorter[T]) cancelled() bool {
+	if x.done == nil {
+		return false
+	}
+	select {
+	case <-x.done:
+		if x.stopped.CompareAndSwap(false, true) && x.traced {
+			trace.Log(x.ctx, "cancel", context.Cause(x.ctx).Error())
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// spawn runs f on a new goroutine tracked by wg, or on the calling goroutine
+// when the goroutine limit has been reached. Once the sort is cancelled it
+// does not run f at all.
+func (x *sorter[T]) spawn(wg *sync.WaitGroup, f func()) {
+	if x.cancelled() {
+		return
+	}
+	if x.sem != nil {
+		select {
+		case x.sem <- struct{}{}:
+		default:
+			x.logLimit()
+			f()
+			return
+		}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if x.sem != nil {
+			defer func() { <-x.sem }()
+		}
+		f()
+	}()
+}
+
+/* Sequential */
+
+func (x *sorter[T]) sequential(p span[T]) {
+	if len(p.s) > 1 {
+		if x.cancelled() {
+			return
+		}
+		left, right, middle := p.split()
+		x.sequential(left)
+		x.sequential(right)
+		if x.cancelled() {
+			return
+		}
+		x.merge(p, middle)
+	}
+}
+
+func (x *sorter[T]) v1(p span[T]) {
+	if len(p.s) > 1 {
+		r := x.startRegion("split", p)
+		left, right, middle := p.split()
+
+		var wg sync.WaitGroup
+		x.spawn(&wg, func() { x.v1(left) })
+		x.spawn(&wg, func() { x.v1(right) })
+
+		// Wait that the two goroutines are completed
+		wg.Wait()
+		endRegion(r)
+		x.parallelMerge(p, middle)
+	}
+}
+
+func (x *sorter[T]) v2(p span[T]) {
+	if len(p.s) > 1 {
+		if len(p.s) <= x.threshold { // Sequential
+			x.cutoff(p, "len <= threshold")
+			return
+		}
+
+		r := x.startRegion("split", p)
+		left, right, middle := p.split()
+
+		var wg sync.WaitGroup
+		x.spawn(&wg, func() { x.v2(left) })
+		x.spawn(&wg, func() { x.v2(right) })
+
+		wg.Wait()
+		endRegion(r)
+		x.parallelMerge(p, middle)
+	}
+}
+
+func (x *sorter[T]) v3(p span[T]) {
+	if len(p.s) > 1 {
+		if len(p.s) <= x.threshold { // Sequential
+			x.cutoff(p, "len <= threshold")
+			return
+		}
+
+		r := x.startRegion("split", p)
+		left, right, middle := p.split()
+
+		var wg sync.WaitGroup
+		x.spawn(&wg, func() { x.v3(left) })
+		x.v3(right)
+
+		wg.Wait()
+		endRegion(r)
+		x.parallelMerge(p, middle)
+	}
+}
+
+// v4 spends one unit of depth per split. Each split doubles the number of
+// goroutines working, so a budget of ceil(log2(GOMAXPROCS)) keeps one per P.
+func (x *sorter[T]) v4(p span[T], depth int) {
+	if len(p.s) > 1 {
+		if depth <= 0 { // Budget spent
+			x.cutoff(p, "goroutine budget spent")
+			return
+		}
+
+		r := x.startRegion("split", p)
+		left, right, middle := p.split()
+
+		var wg sync.WaitGroup
+		x.spawn(&wg, func() { x.v4(left, depth-1) })
+		x.v4(right, depth-1)
+
+		wg.Wait()
+		endRegion(r)
+		x.parallelMerge(p, middle)
+	}
+}
+
+// parallelMerge merges the halves sorted by a parallel split, unless the
+// sort has been cancelled in the meantime.
+func (x *sorter[T]) parallelMerge(p span[T], middle int) {
+	if x.cancelled() {
+		return
+	}
+	r := x.startRegion("merge", p)
+	x.merge(p, middle)
+	endRegion(r)
+}
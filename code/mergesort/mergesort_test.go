@@ -0,0 +1,198 @@
+package mergesort
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var strategies = []Strategy{Sequential, V1, V2, V3, V4}
+
+var mergeModes = []MergeMode{Allocating, PingPong}
+
+func randomInts(n int) []int {
+	r := rand.New(rand.NewSource(1))
+	s := make([]int, n)
+	for i := range s {
+		s[i] = r.Intn(999) - r.Intn(999)
+	}
+	return s
+}
+
+func Test_Sort(t *testing.T) {
+	for _, st := range strategies {
+		for _, mode := range mergeModes {
+			for _, n := range []int{0, 1, 2, 9, DefaultThreshold + 1, 3 * DefaultThreshold} {
+				inp := randomInts(n)
+				exp := slices.Clone(inp)
+				slices.Sort(exp)
+
+				Sort(inp, WithStrategy(st), WithMergeMode(mode), WithMaxGoroutines(4))
+				if !slices.Equal(inp, exp) {
+					t.Errorf("%v/%v: for %d elements, got an unsorted result", st, mode, n)
+				}
+			}
+		}
+	}
+}
+
+func Test_SortFunc(t *testing.T) {
+	type person struct {
+		name string
+		age  int
+	}
+	inp := []person{{"d", 30}, {"a", 20}, {"c", 30}, {"b", 10}}
+	exp := []person{{"b", 10}, {"a", 20}, {"d", 30}, {"c", 30}}
+
+	for _, st := range strategies {
+		for _, mode := range mergeModes {
+			s := slices.Clone(inp)
+			SortFunc(s, func(a, b person) int { return a.age - b.age }, WithStrategy(st), WithMergeMode(mode))
+			if !slices.Equal(s, exp) {
+				t.Errorf("%v/%v: expected stable order %v but got %v", st, mode, exp, s)
+			}
+		}
+	}
+
+	words := []string{"Go", "golang", "gopher", "GC"}
+	SortFunc(words, strings.Compare)
+	if !slices.IsSorted(words) {
+		t.Errorf("expected sorted words but got %v", words)
+	}
+}
+
+func Test_V4_GOMAXPROCS(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	for _, procs := range []int{1, 2, 3, 8, 18} {
+		runtime.GOMAXPROCS(procs)
+		inp := randomInts(3 * DefaultThreshold)
+		Sort(inp, WithStrategy(V4))
+		if !slices.IsSorted(inp) {
+			t.Errorf("GOMAXPROCS=%d: got an unsorted result", procs)
+		}
+	}
+}
+
+func Test_SortContext(t *testing.T) {
+	inp := randomInts(3 * DefaultThreshold)
+	exp := slices.Clone(inp)
+	slices.Sort(exp)
+
+	for _, st := range strategies {
+		s := slices.Clone(inp)
+		if err := SortContext(context.Background(), s, WithStrategy(st), WithMaxGoroutines(1000)); err != nil || !slices.Equal(s, exp) {
+			t.Errorf("%v: got %v and an unsorted result", st, err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		s = slices.Clone(inp)
+		if err := SortContext(ctx, s, WithStrategy(st)); err != context.Canceled || !slices.Equal(s, inp) {
+			t.Errorf("%v: a cancelled context got %v and a modified input", st, err)
+		}
+	}
+}
+
+// Test_SortContext_Leak cancels every strategy midway, from inside the
+// comparison, and checks that no goroutine outlives the call.
+func Test_SortContext_Leak(t *testing.T) {
+	inp := randomInts(1 << 16)
+	exp := sortedCopy(inp)
+	before := runtime.NumGoroutine()
+
+	for _, st := range strategies {
+		for _, mode := range mergeModes {
+			ctx, cancel := context.WithCancel(context.Background())
+			var calls atomic.Int64
+			byValue := func(a, b int) int {
+				if calls.Add(1) == 10000 {
+					cancel()
+				}
+				return a - b
+			}
+
+			s := slices.Clone(inp)
+			err := SortFuncContext(ctx, s, byValue, WithStrategy(st), WithMergeMode(mode), WithThreshold(64), WithMaxGoroutines(1000))
+			cancel()
+			if err != context.Canceled {
+				t.Errorf("%v/%v: expected context.Canceled but got %v", st, mode, err)
+			}
+			if !slices.Equal(sortedCopy(s), exp) {
+				t.Errorf("%v/%v: the cancelled sort lost or duplicated elements", st, mode)
+			}
+		}
+	}
+
+	// Returning goroutines may still be on their way out after wg.Done.
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > before && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines leaked", n-before)
+	}
+}
+
+func sortedCopy(s []int) []int {
+	s = slices.Clone(s)
+	slices.Sort(s)
+	return s
+}
+
+func Test_ParseStrategy(t *testing.T) {
+	for _, st := range strategies {
+		got, err := ParseStrategy(st.String())
+		if err != nil || got != st {
+			t.Errorf("ParseStrategy(%q) = %v, %v", st.String(), got, err)
+		}
+	}
+	if _, err := ParseStrategy("v9"); err == nil {
+		t.Errorf("expected an error for an unknown strategy")
+	}
+
+	for _, mode := range mergeModes {
+		got, err := ParseMergeMode(mode.String())
+		if err != nil || got != mode {
+			t.Errorf("ParseMergeMode(%q) = %v, %v", mode.String(), got, err)
+		}
+	}
+}
+
+func benchmarkSort(b *testing.B, opts ...Option) {
+	inp := randomInts(1 << 16)
+	s := make([]int, len(inp))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(s, inp)
+		Sort(s, opts...)
+	}
+}
+
+// run: go test -run none -bench MergeMode -benchmem
+// study: allocs/op and gc/op of the Allocating mode against PingPong, which
+// allocates one scratch buffer per sort.
+func Benchmark_MergeMode(b *testing.B) {
+	for _, st := range strategies[1:] {
+		for _, mode := range mergeModes {
+			b.Run(st.String()+"/"+mode.String(), func(b *testing.B) {
+				var before, after runtime.MemStats
+				runtime.ReadMemStats(&before)
+				benchmarkSort(b, WithStrategy(st), WithMergeMode(mode))
+				runtime.ReadMemStats(&after)
+				b.ReportMetric(float64(after.NumGC-before.NumGC)/float64(b.N), "gc/op")
+			})
+		}
+	}
+}
+
+func Benchmark_Sequential(b *testing.B) { benchmarkSort(b, WithStrategy(Sequential)) }
+func Benchmark_V1(b *testing.B)         { benchmarkSort(b, WithStrategy(V1)) }
+func Benchmark_V2(b *testing.B)         { benchmarkSort(b, WithStrategy(V2)) }
+func Benchmark_V3(b *testing.B)         { benchmarkSort(b, WithStrategy(V3)) }
+func Benchmark_V4(b *testing.B)         { benchmarkSort(b, WithStrategy(V4)) }
@@ -0,0 +1,114 @@
+package mergesort
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/sathishvj/optimizing-go-programs/code/dataset"
+)
+
+// checkSort reports whether got, the result of sorting inp, is sorted, is a
+// permutation of inp and equals what slices.Sort makes of inp.
+func checkSort(t *testing.T, name string, inp, got []int) {
+	t.Helper()
+	if !slices.IsSorted(got) {
+		t.Errorf("%s: result is not sorted", name)
+	}
+
+	counts := make(map[int]int, len(inp))
+	for _, v := range inp {
+		counts[v]++
+	}
+	for _, v := range got {
+		counts[v]--
+	}
+	for v, c := range counts {
+		if c != 0 {
+			t.Errorf("%s: result is not a permutation of the input: %d appears %+d times too often", name, v, -c)
+			break
+		}
+	}
+
+	exp := slices.Clone(inp)
+	slices.Sort(exp)
+	if !slices.Equal(got, exp) {
+		t.Errorf("%s: result differs from slices.Sort", name)
+	}
+}
+
+// cutoffSizes returns sizes at and around one and two times threshold,
+// where V2 and V3 switch between splitting and sorting sequentially.
+func cutoffSizes(threshold int) []int {
+	var sizes []int
+	for _, n := range []int{threshold, 2 * threshold} {
+		for _, d := range []int{-1, 0, 1} {
+			if n+d >= 0 {
+				sizes = append(sizes, n+d)
+			}
+		}
+	}
+	return sizes
+}
+
+// run: go test -race -run Properties
+func Test_Properties(t *testing.T) {
+	for _, threshold := range []int{1, 2, 7, 64, DefaultThreshold} {
+		for _, n := range cutoffSizes(threshold) {
+			for _, d := range dataset.Dists {
+				inp := dataset.New(uint64(n)).Ints(d, n)
+				for _, st := range strategies {
+					for _, mode := range mergeModes {
+						got := slices.Clone(inp)
+						// V1 starts two goroutines per element, which would
+						// exceed the race detector's limit of 8128 running at once.
+						Sort(got, WithStrategy(st), WithMergeMode(mode), WithThreshold(threshold), WithMaxGoroutines(1000))
+						checkSort(t, fmt.Sprintf("%v/%v threshold=%d n=%d %v", st, mode, threshold, n, d), inp, got)
+					}
+				}
+			}
+		}
+	}
+}
+
+// run: go test -fuzz Fuzz_Sort -fuzztime 30s
+func Fuzz_Sort(f *testing.F) {
+	f.Add([]byte{}, uint8(0), uint8(0), false)
+	f.Add([]byte{3, 1, 2}, uint8(1), uint8(1), true)
+	f.Add([]byte("the quick brown fox jumps over the lazy dog"), uint8(4), uint8(2), false)
+	f.Add(make([]byte, 300), uint8(16), uint8(3), true)
+
+	f.Fuzz(func(t *testing.T, data []byte, threshold, strategy uint8, pingPong bool) {
+		st := strategies[int(strategy)%len(strategies)]
+		mode := Allocating
+		if pingPong {
+			mode = PingPong
+		}
+		opts := []Option{WithStrategy(st), WithMergeMode(mode), WithThreshold(int(threshold)), WithMaxGoroutines(1000)}
+		name := fmt.Sprintf("%v/%v threshold=%d n=%d", st, mode, threshold, len(data))
+
+		inp := make([]int, len(data))
+		for i, b := range data {
+			inp[i] = int(int8(b))
+		}
+		got := slices.Clone(inp)
+		Sort(got, opts...)
+		checkSort(t, name, inp, got)
+
+		// Sort by a key with many ties and compare with the standard
+		// library's stable sort to check that equal elements keep their order.
+		type item struct{ key, pos int }
+		items := make([]item, len(data))
+		for i, b := range data {
+			items[i] = item{int(b % 4), i}
+		}
+		byKey := func(a, b item) int { return cmp.Compare(a.key, b.key) }
+		exp := slices.Clone(items)
+		slices.SortStableFunc(exp, byKey)
+		SortFunc(items, byKey, opts...)
+		if !slices.Equal(items, exp) {
+			t.Errorf("%s: equal elements are out of their original order", name)
+		}
+	})
+}
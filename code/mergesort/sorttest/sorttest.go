@@ -0,0 +1,101 @@
+// Package sorttest benchmarks sort functions on fresh input every iteration.
+//
+// Sorting a package-level slice in place in a benchmark loop measures the
+// first iteration on the real data and every later one on sorted data.
+// Benchmark instead hands each iteration its own unsorted copy, made ahead of
+// time with the timer stopped:
+//
+//	func Benchmark_v3(b *testing.B) {
+//		sorttest.Benchmark(b, func(s []int) {
+//			mergesort.Sort(s, mergesort.WithStrategy(mergesort.V3))
+//		})
+//	}
+//
+//	go test -bench v3/n=1000000/ -benchmem
+package sorttest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sathishvj/optimizing-go-programs/code/dataset"
+)
+
+// Sizes are the input lengths Benchmark runs.
+var Sizes = []int{1e3, 1e4, 1e5, 1e6, 1e7}
+
+// ringBytes bounds the memory taken by the copies of one input.
+const ringBytes = 64 << 20
+
+// Benchmark runs sort as a sub-benchmark per size in Sizes and per
+// distribution in dataset.Dists, named like "n=1000/uniform". Besides ns/op
+// it reports ns/element, which stays comparable across sizes.
+func Benchmark(b *testing.B, sort func(s []int)) {
+	for _, n := range Sizes {
+		for _, d := range dataset.Dists {
+			b.Run(fmt.Sprintf("n=%d/%v", n, d), func(b *testing.B) {
+				benchmark(b, dataset.New(1).Ints(d, n), sort)
+			})
+		}
+	}
+}
+
+// NsPerElement benchmarks sort on fresh copies of inp with
+// testing.Benchmark, for use outside go test, and returns the time per
+// element.
+func NsPerElement(inp []int, sort func(s []int)) float64 {
+	r := testing.Benchmark(func(b *testing.B) { benchmark(b, inp, sort) })
+	return r.Extra["ns/element"]
+}
+
+func benchmark(b *testing.B, inp []int, sort func(s []int)) {
+	r := newRing(inp, b.N)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sort(r.next(b))
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N)/float64(max(len(inp), 1)), "ns/element")
+}
+
+// ring holds copies of an input, made in one go outside the timed region.
+// Once every copy has been sorted, next stops the timer and copies the input
+// over all of them again.
+type ring struct {
+	inp    []int
+	copies [][]int
+	i      int
+}
+
+// newRing returns a ring of as many copies of inp as iterations, or as many
+// as fit in ringBytes, whichever is fewer, but at least one.
+func newRing(inp []int, iterations int) *ring {
+	k := max(1, min(iterations, ringBytes/(8*max(len(inp), 1))))
+	backing := make([]int, k*len(inp))
+	r := &ring{inp: inp, copies: make([][]int, k)}
+	for j := range r.copies {
+		r.copies[j] = backing[j*len(inp) : (j+1)*len(inp) : (j+1)*len(inp)]
+	}
+	r.refill()
+	return r
+}
+
+func (r *ring) refill() {
+	for _, c := range r.copies {
+		copy(c, r.inp)
+	}
+	r.i = 0
+}
+
+// next returns a copy of the input that has not been handed out since it
+// was last refilled.
+func (r *ring) next(b *testing.B) []int {
+	if r.i == len(r.copies) {
+		b.StopTimer()
+		r.refill()
+		b.StartTimer()
+	}
+	s := r.copies[r.i]
+	r.i++
+	return s
+}
@@ -0,0 +1,28 @@
+package sorttest
+
+import (
+	"slices"
+	"testing"
+)
+
+func Test_ring(t *testing.T) {
+	inp := []int{3, 1, 2}
+	for _, iterations := range []int{1, 2, 5} {
+		r := newRing(inp, iterations)
+		// Every iteration, including those after a refill, must get the
+		// original order, however the previous ones were sorted.
+		testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < 3*iterations; i++ {
+				s := r.next(b)
+				if !slices.Equal(s, inp) {
+					t.Errorf("%d iterations: got %v at iteration %d, expected %v", iterations, s, i, inp)
+				}
+				slices.Sort(s)
+			}
+		})
+	}
+}
+
+func Benchmark_slicesSort(b *testing.B) {
+	Benchmark(b, slices.Sort[[]int])
+}
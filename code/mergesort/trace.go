@@ -0,0 +1,66 @@
+package mergesort
+
+import (
+	"context"
+	"fmt"
+	"runtime/trace"
+)
+
+// The annotations WithTracing adds to an execution trace. In the "User-defined
+// tasks" view each sort is a task named after its strategy; in the
+// "User-defined regions" view
+//
+//	split depth=d len=n       spawning and waiting for the two halves of a parallel split
+//	merge depth=d len=n       merging them again
+//	sequential depth=d len=n  the whole sequential sort below a cutoff
+//
+// with one region type per depth, so the time spent at each level of the
+// recursion can be compared across strategies. The task log has a "cutoff"
+// entry for every decision to stop splitting or to stop starting goroutines.
+//
+// Only parallel splits get regions. Sequential subtrees would add a region
+// per element and bury everything else.
+//
+// The regions are started with trace.StartRegion rather than
+// trace.WithRegion, whose closure would cost an allocation per split even
+// when tracing is off.
+
+// startTask starts the task for one sort of n elements.
+func (x *sorter[T]) startTask(ctx context.Context, n int) *trace.Task {
+	ctx, task := trace.NewTask(ctx, "mergesort "+x.strategy.String())
+	x.ctx, x.traced = ctx, true
+	trace.Logf(ctx, "config", "len=%d threshold=%d maxGoroutines=%d mergeMode=%v", n, x.threshold, x.maxGoroutines, x.mergeMode)
+	return task
+}
+
+// startRegion starts a region for p, or returns nil when not traced.
+func (x *sorter[T]) startRegion(kind string, p span[T]) *trace.Region {
+	if !x.traced {
+		return nil
+	}
+	return trace.StartRegion(x.ctx, fmt.Sprintf("%s depth=%d len=%d", kind, p.depth, len(p.s)))
+}
+
+// endRegion ends a region returned by startRegion.
+func endRegion(r *trace.Region) {
+	if r != nil {
+		r.End()
+	}
+}
+
+// cutoff sorts p sequentially on the calling goroutine, logging why.
+func (x *sorter[T]) cutoff(p span[T], reason string) {
+	if x.traced {
+		trace.Logf(x.ctx, "cutoff", "depth=%d len=%d: %s", p.depth, len(p.s), reason)
+	}
+	r := x.startRegion("sequential", p)
+	x.sequential(p)
+	endRegion(r)
+}
+
+// logLimit logs that the goroutine limit made a split run inline.
+func (x *sorter[T]) logLimit() {
+	if x.traced {
+		trace.Logf(x.ctx, "cutoff", "goroutine limit %d reached: running inline", x.maxGoroutines)
+	}
+}
@@ -0,0 +1,36 @@
+package mergesort
+
+import (
+	"bytes"
+	"runtime/trace"
+	"slices"
+	"testing"
+)
+
+func Test_WithTracing(t *testing.T) {
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skip("cannot start tracing:", err)
+	}
+	s := randomInts(1024)
+	Sort(s, WithStrategy(V3), WithThreshold(256), WithMaxGoroutines(1), WithTracing(true))
+	trace.Stop()
+
+	if !slices.IsSorted(s) {
+		t.Errorf("got an unsorted result")
+	}
+	// The names of tasks, regions and log categories and messages are kept
+	// verbatim in the trace's string table.
+	for _, want := range []string{
+		"mergesort v3",
+		"split depth=0 len=1024",
+		"merge depth=1 len=512",
+		"sequential depth=2 len=256",
+		"depth=2 len=256: len <= threshold",
+		"goroutine limit 1 reached: running inline",
+	} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("expected %q in the trace", want)
+		}
+	}
+}
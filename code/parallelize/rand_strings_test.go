@@ -1,15 +1,11 @@
 package main
 
 import (
-	"math/rand"
 	"sync"
 	"testing"
-	"time"
-)
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
+	"github.com/sathishvj/optimizing-go-programs/code/dataset"
+)
 
 var s []string
 
@@ -58,12 +54,26 @@ func Benchmark_Locked_Mutex(b *testing.B) {
 	}
 }
 
-var letters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+var letters = []rune(dataset.Letters)
+
+// rng is the one generator every goroutine shares, like the global source
+// behind rand.Intn. It is seeded, so the strings are the same on every run,
+// and locked for each character, so concurrent calls contend for it.
+var (
+	rngMu sync.Mutex
+	rng   = dataset.New(42).Rand()
+)
+
+func intn(n int) int {
+	rngMu.Lock()
+	defer rngMu.Unlock()
+	return rng.IntN(n)
+}
 
 func RandString(n int) string {
 	b := make([]rune, n)
 	for i := range b {
-		b[i] = letters[rand.Intn(len(letters))]
+		b[i] = letters[intn(len(letters))]
 	}
 	//time.Sleep(10 * time.Microsecond)
 	return string(b)
@@ -1,18 +1,26 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"os"
 	"regexp"
+	"unicode/utf8"
 )
 
 func main() {
+	scan := flag.Bool("scan", false, "match with the byte scanner instead of the regexp")
+	flag.Parse()
+
 	var data string
-	if len(os.Args) == 2 {
-		data = os.Args[1]
+	if flag.NArg() == 1 {
+		data = flag.Arg(0)
 	}
 
-	id, ok := isGopher(data)
+	check := isGopher
+	if *scan {
+		check = isGopherScan
+	}
+	id, ok := check(data)
 	if !ok {
 		id = "stranger"
 	}
@@ -27,3 +35,28 @@ func isGopher(email string) (string, bool) {
 	}
 	return "", false
 }
+
+// isGopherScan is isGopher without the regexp: it checks the bytes of
+// ^([[:alpha:]]+)@golang.org$ by hand. Note that the unescaped . matches
+// any one character but a newline, as it does in the regexp.
+func isGopherScan(email string) (string, bool) {
+	i := 0
+	for i < len(email) && ('a' <= email[i] && email[i] <= 'z' || 'A' <= email[i] && email[i] <= 'Z') {
+		i++
+	}
+	const prefix, suffix = "@golang", "org"
+	rest := email[i:]
+	if i == 0 || len(rest) < len(prefix)+1+len(suffix) ||
+		rest[:len(prefix)] != prefix || rest[len(rest)-len(suffix):] != suffix {
+		return "", false
+	}
+
+	// the one character in between; an invalid byte counts as one, as
+	// it does for the regexp
+	dot := rest[len(prefix) : len(rest)-len(suffix)]
+	r, size := utf8.DecodeRuneInString(dot)
+	if size != len(dot) || r == '\n' {
+		return "", false
+	}
+	return email[:i], true
+}
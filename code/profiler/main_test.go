@@ -55,3 +55,56 @@ func Benchmark_isGopher(b *testing.B) {
 		isGopher(tcs[0].in)
 	}
 }
+
+var gopherSeeds = []string{
+	"", "a@email.com", "a@golang.org", "Gopher@golang.org", "@golang.org",
+	"a1@golang.org", "a@golang.org\n", "a@golangXorg", "a@golang\norg", "a@golang世org",
+	"a@golang\xfforg", "a@golang\xe4\xb8org", "a@golang..org", "a@golangorg", "ab@golang.orgorg",
+}
+
+func Test_isGopherScan(t *testing.T) {
+	tcs := []struct {
+		in    string
+		exp   bool
+		expId string
+	}{
+		{"", false, ""},
+		{"a@email.com", false, ""},
+		{"a@golang.org", true, "a"},
+		{"Gopher@golang.org", true, "Gopher"},
+		{"a@golangXorg", true, "a"},
+		{"a@golang\norg", false, ""},
+		{"a@golang.org\n", false, ""},
+	}
+
+	for _, tc := range tcs {
+		id, ok := isGopherScan(tc.in)
+		if ok != tc.exp {
+			t.Errorf("For input %q, expected: %t but got: %t", tc.in, tc.exp, ok)
+		}
+		if id != tc.expId {
+			t.Errorf("For input %q, expected: %s but got: %s", tc.in, tc.expId, id)
+		}
+	}
+}
+
+// Fuzz_isGopher checks that the scanner agrees with the regexp on every
+// input.
+func Fuzz_isGopher(f *testing.F) {
+	for _, s := range gopherSeeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		expId, exp := isGopher(in)
+		id, ok := isGopherScan(in)
+		if ok != exp || id != expId {
+			t.Errorf("For input %q, expected: %q, %t but got: %q, %t", in, expId, exp, id, ok)
+		}
+	})
+}
+
+func Benchmark_isGopherScan(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		isGopherScan("a@golang.org")
+	}
+}
@@ -0,0 +1,98 @@
+// Compare benchmarks the radix sorts against mergesort v3, sort.Ints and
+// slices.Sort on the same generated data, and prints ns/element per size
+// and distribution with the fastest sort of each row.
+//
+// go run ./cmd/compare
+// go run ./cmd/compare -sizes 1e3,1e5,1e7 -dists uniform,few-unique,sorted -format md
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sathishvj/optimizing-go-programs/code/dataset"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort/experiment"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort/sorttest"
+	"github.com/sathishvj/optimizing-go-programs/code/radixsort"
+)
+
+var sorts = []struct {
+	name string
+	sort func([]int)
+}{
+	{"radix", radixsort.Sort[int]},
+	{"radix parallel", func(s []int) { radixsort.SortParallel(s, 0) }},
+	{"mergesort v3", func(s []int) { mergesort.Sort(s, mergesort.WithStrategy(mergesort.V3)) }},
+	{"sort.Ints", sort.Ints},
+	{"slices.Sort", slices.Sort[[]int]},
+}
+
+func main() {
+	var (
+		sizesFlag = flag.String("sizes", "1e3,1e4,1e5,1e6,1e7", "comma separated input sizes")
+		distsFlag = flag.String("dists", "uniform", "comma separated distributions: "+strings.Join(distNames(), ", "))
+		format    = flag.String("format", "text", "output format: text, md, csv")
+	)
+	flag.Parse()
+
+	var sizes []int
+	for _, f := range strings.Split(*sizesFlag, ",") {
+		n, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil || n < 1 {
+			log.Fatalf("invalid size %q", f)
+		}
+		sizes = append(sizes, int(n))
+	}
+	var dists []dataset.Dist
+	for _, f := range strings.Split(*distsFlag, ",") {
+		d, err := dataset.ParseDist(strings.TrimSpace(f))
+		if err != nil {
+			log.Fatal(err)
+		}
+		dists = append(dists, d)
+	}
+
+	t := experiment.Table{Header: []string{"n", "dist"}}
+	for _, s := range sorts {
+		t.Header = append(t.Header, s.name)
+	}
+	t.Header = append(t.Header, "fastest")
+
+	for _, n := range sizes {
+		for _, d := range dists {
+			inp := dataset.New(1).Ints(d, n)
+			row := []any{n, d}
+			best := 0
+			times := make([]float64, len(sorts))
+			for i, s := range sorts {
+				times[i] = sorttest.NsPerElement(inp, s.sort)
+				if times[i] < times[best] {
+					best = i
+				}
+				row = append(row, fmt.Sprintf("%.1f", times[i]))
+			}
+			t.Add(append(row, sorts[best].name)...)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "ns/element, GOMAXPROCS=%d\n", runtime.GOMAXPROCS(0))
+	if err := t.Write(os.Stdout, *format); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func distNames() []string {
+	var names []string
+	for _, d := range dataset.Dists {
+		names = append(names, d.String())
+	}
+	return names
+}
@@ -0,0 +1,168 @@
+// Package radixsort is a least-significant-digit radix sort for integers, as
+// a baseline for the comparison sorts in the mergesort package.
+//
+// It sorts a byte at a time, from the lowest byte to the highest, with one
+// counting pass and one scatter pass per byte. Passes where every element
+// has the same byte, such as the high bytes of small numbers, are skipped.
+//
+//	radixsort.Sort(s)            // sequential
+//	radixsort.SortParallel(s, 0) // one worker per P
+package radixsort
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Integer is the set of element types the sorts accept.
+type Integer interface {
+	~int | ~uint32 | ~uint64
+}
+
+// minBlock is the fewest elements SortParallel gives a worker. Below that,
+// starting the workers costs more than they save.
+const minBlock = 1 << 14
+
+// layout returns the width of T in bits, and the mask that flips the sign
+// bit of a signed T, which makes the order of the unsigned keys match the
+// order of the values.
+func layout[T Integer]() (flip uint64, width int) {
+	for x := T(1); x != 0; x <<= 1 {
+		width++
+	}
+	var zero T
+	if ^zero < 0 {
+		flip = 1 << (width - 1)
+	}
+	return flip, width
+}
+
+// Sort sorts s in ascending order. It allocates one buffer of len(s).
+func Sort[T Integer](s []T) {
+	n := len(s)
+	if n < 2 {
+		return
+	}
+	flip, width := layout[T]()
+	digits := width / 8
+
+	// The number of elements with each byte value does not depend on the
+	// order, so one pass counts them for every digit.
+	var counts [8][256]int
+	for _, v := range s {
+		k := uint64(v) ^ flip
+		for d := 0; d < digits; d++ {
+			counts[d][uint8(k>>(8*d))]++
+		}
+	}
+
+	buf := make([]T, n)
+	src, dst := s, buf
+	for d := 0; d < digits; d++ {
+		shift := 8 * d
+		c := &counts[d]
+		if c[uint8((uint64(s[0])^flip)>>shift)] == n {
+			continue // every element has the same byte
+		}
+
+		offset := 0
+		for b, count := range c {
+			c[b] = offset
+			offset += count
+		}
+		for _, v := range src {
+			b := uint8((uint64(v) ^ flip) >> shift)
+			dst[c[b]] = v
+			c[b]++
+		}
+		src, dst = dst, src
+	}
+	if &src[0] != &s[0] {
+		copy(s, src)
+	}
+}
+
+// SortParallel sorts s in ascending order with up to workers goroutines, or
+// GOMAXPROCS of them when workers is 0 or less. Each worker takes a
+// contiguous block of the input, counts the bytes in its block and then
+// scatters its block to the offsets those counts give it, so the passes
+// need no locking. Inputs too small to give each worker minBlock elements
+// use fewer workers, down to a plain Sort.
+func SortParallel[T Integer](s []T, workers int) {
+	n := len(s)
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, n/minBlock)
+	if workers <= 1 {
+		Sort(s)
+		return
+	}
+	flip, width := layout[T]()
+	block := (n + workers - 1) / workers
+	bounds := func(w int) (lo, hi int) {
+		return w * block, min((w+1)*block, n)
+	}
+
+	buf := make([]T, n)
+	src, dst := s, buf
+	counts := make([][256]int, workers)
+	for shift := 0; shift < width; shift += 8 {
+		parallel(workers, func(w int) {
+			lo, hi := bounds(w)
+			c := &counts[w]
+			*c = [256]int{}
+			for _, v := range src[lo:hi] {
+				c[uint8((uint64(v)^flip)>>shift)]++
+			}
+		})
+
+		// Turn the counts into where each worker writes each byte value:
+		// all of byte b goes before byte b+1, and within a byte, worker w
+		// writes before worker w+1, which keeps the sort stable.
+		offset, skip := 0, false
+		for b := 0; b < 256; b++ {
+			start := offset
+			for w := range counts {
+				count := counts[w][b]
+				counts[w][b] = offset
+				offset += count
+			}
+			if offset-start == n {
+				skip = true // every element has the same byte
+				break
+			}
+		}
+		if skip {
+			continue
+		}
+
+		parallel(workers, func(w int) {
+			lo, hi := bounds(w)
+			c := &counts[w]
+			for _, v := range src[lo:hi] {
+				b := uint8((uint64(v) ^ flip) >> shift)
+				dst[c[b]] = v
+				c[b]++
+			}
+		})
+		src, dst = dst, src
+	}
+	if &src[0] != &s[0] {
+		copy(s, src)
+	}
+}
+
+// parallel runs f(0) to f(workers-1) at the same time and waits for them.
+func parallel(workers int, f func(w int)) {
+	var wg sync.WaitGroup
+	for w := 1; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f(w)
+		}()
+	}
+	f(0)
+	wg.Wait()
+}
@@ -0,0 +1,81 @@
+package radixsort
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/sathishvj/optimizing-go-programs/code/dataset"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort/sorttest"
+)
+
+// sorts are the variants under test, with SortParallel forced to split
+// small inputs over several workers.
+func sorts[T Integer]() map[string]func([]T) {
+	return map[string]func([]T){
+		"Sort":           Sort[T],
+		"SortParallel/3": func(s []T) { SortParallel(s, 3) },
+		"SortParallel/8": func(s []T) { SortParallel(s, 8) },
+	}
+}
+
+// testSorts checks every variant against slices.Sort on inp.
+func testSorts[T Integer](t *testing.T, name string, inp []T) {
+	t.Helper()
+	exp := slices.Clone(inp)
+	slices.Sort(exp)
+	for variant, sort := range sorts[T]() {
+		s := slices.Clone(inp)
+		sort(s)
+		if !slices.Equal(s, exp) {
+			t.Errorf("%s %s: for %d elements, got an unsorted result", variant, name, len(inp))
+		}
+	}
+}
+
+func Test_Sort(t *testing.T) {
+	g := dataset.New(1)
+	for _, n := range []int{0, 1, 2, 100, minBlock, 5*minBlock + 7} {
+		for _, d := range dataset.Dists {
+			ints := g.Ints(d, n)
+			testSorts(t, "[]int "+d.String(), ints)
+
+			u32 := make([]uint32, n)
+			u64 := make([]uint64, n)
+			mixed := make([]int, n)
+			for i, v := range ints {
+				u32[i] = uint32(v) * 2654435761 // spread over all four bytes
+				u64[i] = uint64(v) << 40
+				mixed[i] = v - n/2
+			}
+			testSorts(t, "[]uint32 "+d.String(), u32)
+			testSorts(t, "[]uint64 "+d.String(), u64)
+			testSorts(t, "negative []int "+d.String(), mixed)
+		}
+	}
+
+	testSorts(t, "extremes", []int{math.MaxInt, -1, 0, math.MinInt, 1, math.MinInt + 1, math.MaxInt - 1})
+	testSorts(t, "extremes", []uint64{math.MaxUint64, 0, 1 << 63, 1<<63 - 1, 1})
+}
+
+func Benchmark_RadixSort(b *testing.B) {
+	sorttest.Benchmark(b, Sort[int])
+}
+
+func Benchmark_RadixSortParallel(b *testing.B) {
+	sorttest.Benchmark(b, func(s []int) { SortParallel(s, 0) })
+}
+
+func Benchmark_MergesortV3(b *testing.B) {
+	sorttest.Benchmark(b, func(s []int) { mergesort.Sort(s, mergesort.WithStrategy(mergesort.V3)) })
+}
+
+func Benchmark_SortInts(b *testing.B) {
+	sorttest.Benchmark(b, sort.Ints)
+}
+
+func Benchmark_SlicesSort(b *testing.B) {
+	sorttest.Benchmark(b, slices.Sort[[]int])
+}
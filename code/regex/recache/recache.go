@@ -0,0 +1,161 @@
+// Package recache is a bounded cache of compiled regular expressions, for
+// patterns that are only known at run time, such as user-defined filters,
+// and so cannot be compiled once into package variables.
+//
+//	c := recache.New(256)
+//	ok, err := c.MatchString(filter, line)
+//
+// A pattern is compiled once, however many goroutines ask for it together,
+// and kept until it is the least recently used of more than the cache's
+// size. Invalid patterns are cached too, with their error.
+package recache
+
+import (
+	"container/list"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// Cache holds up to a fixed number of compiled patterns. It is safe for
+// concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	lru     *list.List // of *entry, the most recently used first
+	entries map[string]*list.Element
+	stats   Stats
+}
+
+type entry struct {
+	pattern string
+	ready   chan struct{} // closed when re and err are set
+	re      *regexp.Regexp
+	err     error
+}
+
+// New returns a cache of up to size patterns. It panics if size is not
+// positive.
+func New(size int) *Cache {
+	if size <= 0 {
+		panic("recache: New needs a positive size")
+	}
+	return &Cache{
+		size:    size,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+// Compile returns the compiled pattern, compiling it if it is not in the
+// cache. Calls that ask for a pattern while it is being compiled wait for
+// that compilation.
+func (c *Cache) Compile(pattern string) (*regexp.Regexp, error) {
+	c.mu.Lock()
+	if el, ok := c.entries[pattern]; ok {
+		c.lru.MoveToFront(el)
+		c.stats.Hits++
+		e := el.Value.(*entry)
+		c.mu.Unlock()
+		<-e.ready
+		return e.re, e.err
+	}
+
+	c.stats.Misses++
+	e := &entry{pattern: pattern, ready: make(chan struct{})}
+	c.entries[pattern] = c.lru.PushFront(e)
+	if c.lru.Len() > c.size {
+		// an entry still compiling may go too; its waiters still get it
+		last := c.lru.Back()
+		c.lru.Remove(last)
+		delete(c.entries, last.Value.(*entry).pattern)
+		c.stats.Evictions++
+	}
+	c.mu.Unlock()
+
+	e.re, e.err = regexp.Compile(pattern)
+	close(e.ready)
+	return e.re, e.err
+}
+
+// MatchString reports whether s contains any match of pattern, like
+// regexp.MatchString but without compiling a cached pattern again.
+func (c *Cache) MatchString(pattern, s string) (bool, error) {
+	re, err := c.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(s), nil
+}
+
+// Match reports whether b contains any match of pattern, like
+// regexp.Match.
+func (c *Cache) Match(pattern string, b []byte) (bool, error) {
+	re, err := c.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.Match(b), nil
+}
+
+// FindStringSubmatch returns the leftmost match of pattern in s and its
+// submatches, as regexp.Regexp.FindStringSubmatch does.
+func (c *Cache) FindStringSubmatch(pattern, s string) ([]string, error) {
+	re, err := c.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re.FindStringSubmatch(s), nil
+}
+
+// Len returns the number of patterns in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Stats returns a snapshot of the cache's counters.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Stats counts what a cache has done.
+type Stats struct {
+	Hits      uint64 // patterns found in the cache, compiled or compiling
+	Misses    uint64 // patterns compiled
+	Evictions uint64 // patterns dropped to make room
+}
+
+// HitRatio returns the fraction of lookups that found their pattern, or 0
+// before the first lookup.
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Sub returns the counts since an earlier snapshot t.
+func (s Stats) Sub(t Stats) Stats {
+	return Stats{Hits: s.Hits - t.Hits, Misses: s.Misses - t.Misses, Evictions: s.Evictions - t.Evictions}
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("hits=%d misses=%d evictions=%d hit=%.1f%%", s.Hits, s.Misses, s.Evictions, 100*s.HitRatio())
+}
+
+// Default is the cache used by the package-level functions.
+var Default = New(256)
+
+// MatchString is Default.MatchString.
+func MatchString(pattern, s string) (bool, error) {
+	return Default.MatchString(pattern, s)
+}
+
+// Compile is Default.Compile.
+func Compile(pattern string) (*regexp.Regexp, error) {
+	return Default.Compile(pattern)
+}
@@ -0,0 +1,127 @@
+package recache
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func Test_Cache(t *testing.T) {
+	c := New(2)
+	for i := 0; i < 3; i++ {
+		ok, err := c.MatchString(`^[a-z]+@golang\.org$`, "gopher@golang.org")
+		if !ok || err != nil {
+			t.Errorf("expected a match but got %v, %v", ok, err)
+		}
+	}
+	m, _ := c.FindStringSubmatch(`^([a-z]+)@golang\.org$`, "gopher@golang.org")
+	if len(m) != 2 || m[1] != "gopher" {
+		t.Errorf("expected the submatch gopher but got %q", m)
+	}
+	if ok, _ := c.Match(`go+`, []byte("gooo")); !ok {
+		t.Errorf("expected a match")
+	}
+
+	exp := Stats{Hits: 2, Misses: 3, Evictions: 1}
+	if got := c.Stats(); got != exp {
+		t.Errorf("expected %v but got %v", exp, got)
+	}
+}
+
+func Test_Cache_LRU(t *testing.T) {
+	c := New(2)
+	for _, p := range []string{"a", "b", "a", "c"} { // b is the least recent when c comes
+		c.Compile(p)
+	}
+	before := c.Stats()
+	c.Compile("a")
+	c.Compile("c")
+	if got := c.Stats().Sub(before); got.Hits != 2 || got.Misses != 0 {
+		t.Errorf("expected a and c kept but got %v", got)
+	}
+	c.Compile("b")
+	if got := c.Stats().Sub(before); got.Misses != 1 || got.Evictions != 1 {
+		t.Errorf("expected b compiled again, evicting a, but got %v", got)
+	}
+	if n := c.Len(); n != 2 {
+		t.Errorf("expected 2 patterns but got %d", n)
+	}
+}
+
+func Test_Cache_Invalid(t *testing.T) {
+	c := New(2)
+	_, err1 := c.MatchString(`a(b`, "ab")
+	_, err2 := c.MatchString(`a(b`, "ab")
+	if err1 == nil || err1 != err2 {
+		t.Errorf("expected the same compile error twice but got %v and %v", err1, err2)
+	}
+	if s := c.Stats(); s.Misses != 1 {
+		t.Errorf("expected one compile but got %v", s)
+	}
+}
+
+func Test_Cache_OneCompile(t *testing.T) {
+	c := New(2)
+	var wg sync.WaitGroup
+	res := make([]*regexp.Regexp, 100)
+	for i := range res {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res[i], _ = c.Compile(`^[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]+$`)
+		}()
+	}
+	wg.Wait()
+	if s := c.Stats(); s.Misses != 1 || s.Hits != 99 {
+		t.Errorf("expected one compile but got %v", s)
+	}
+	for _, re := range res {
+		if re != res[0] {
+			t.Fatalf("expected every call to get the same Regexp")
+		}
+	}
+}
+
+// patterns returns n distinct filters, like ones users might define.
+func patterns(n int) []string {
+	p := make([]string, n)
+	for i := range p {
+		p[i] = fmt.Sprintf(`^[a-z]+%d@(golang|example)\.(org|com)$`, i)
+	}
+	return p
+}
+
+// benchmarkMatch matches against the patterns in turn, with a cache of 64.
+// Hit heavy sets fit in the cache; churn heavy ones do not, so with LRU
+// every lookup misses.
+func benchmarkMatch(b *testing.B, n int) {
+	p := patterns(n)
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			regexp.MatchString(p[i%n], "gopher7@golang.org")
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		c := New(64)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			c.MatchString(p[i%n], "gopher7@golang.org")
+		}
+		b.ReportMetric(100*c.Stats().HitRatio(), "hit%")
+	})
+	b.Run("cached parallel", func(b *testing.B) {
+		c := New(64)
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				c.MatchString(p[i%n], "gopher7@golang.org")
+			}
+		})
+		b.ReportMetric(100*c.Stats().HitRatio(), "hit%")
+	})
+}
+
+func Benchmark_HitHeavy(b *testing.B)   { benchmarkMatch(b, 16) }
+func Benchmark_ChurnHeavy(b *testing.B) { benchmarkMatch(b, 1000) }
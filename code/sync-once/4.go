@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+
+	"github.com/sathishvj/optimizing-go-programs/code/sync-once/lazy"
+)
+
+var s = `
+<h1>{{.PageTitle}}<h1>
+<ul>
+    {{range .Todos}}
+        {{if .Done}}
+            <li class="done">{{.Title}}</li>
+        {{else}}
+            <li>{{.Title}}</li>
+        {{end}}
+    {{end}}
+</ul>
+`
+
+// like sync.Once, but a parse error is returned rather than a panic, and a
+// failed parse is tried again on the next call
+var home = lazy.New(func() (*template.Template, error) {
+	fmt.Println("within init")
+	return template.New("").Parse(s)
+})
+
+func f() error {
+	// only done once and when used
+	t, err := home.Get()
+	if err != nil {
+		return err
+	}
+
+	// do task with template
+	_ = t
+	return nil
+}
+
+func main() {
+	for i := 0; i < 10000; i++ {
+		if err := f(); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Inittrace runs a Go program with GODEBUG=inittrace=1 and reports what
+// initializing each of its packages cost, or diffs two programs.
+//
+// go build -o eager ./example/eager && go build -o lazy ./example/lazy
+// go run ./cmd/inittrace -count 10 ./eager
+// go run ./cmd/inittrace -diff -sort bytes -top 10 ./eager ./lazy
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sathishvj/optimizing-go-programs/code/sync-once/inittrace"
+)
+
+func main() {
+	var (
+		count  = flag.Int("count", 5, "runs of each program, reporting the median")
+		diff   = flag.Bool("diff", false, "diff two programs, given without arguments")
+		sortBy = flag.String("sort", "clock", "sort by "+strings.Join(inittrace.Keys, ", "))
+		top    = flag.Int("top", 0, "only the first n packages (default all)")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: inittrace [flags] program [args...]\n       inittrace -diff [flags] program1 program2\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 || *diff && flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	run := func(name string, args ...string) []inittrace.Init {
+		inits, err := inittrace.Run(context.Background(), *count, os.Stderr, name, args...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return inits
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if *diff {
+		a, b := run(flag.Arg(0)), run(flag.Arg(1))
+		deltas := inittrace.Diff(a, b)
+		if err := inittrace.SortDiff(deltas, *sortBy); err != nil {
+			log.Fatal(err)
+		}
+		row(tw, "package", "clock ms A", "clock ms B", "Δ", "bytes A", "bytes B", "Δ", "allocs A", "allocs B", "Δ")
+		for _, d := range limit(deltas, *top) {
+			deltaRow(tw, d)
+		}
+		ta, tb := inittrace.Total(a), inittrace.Total(b)
+		deltaRow(tw, inittrace.Delta{Package: ta.Package, A: ta, B: tb})
+		row(tw, "main starts ms", ms(ta.At), ms(tb.At), signedMs(tb.At-ta.At))
+	} else {
+		inits := run(flag.Arg(0), flag.Args()[1:]...)
+		total := inittrace.Total(inits)
+		if err := inittrace.Sort(inits, *sortBy); err != nil {
+			log.Fatal(err)
+		}
+		row(tw, "package", "at ms", "clock ms", "bytes", "allocs")
+		for _, in := range limit(inits, *top) {
+			row(tw, in.Package, ms(in.At), ms(in.Clock), in.Bytes, in.Allocs)
+		}
+		row(tw, total.Package, ms(total.At), ms(total.Clock), total.Bytes, total.Allocs)
+	}
+	if err := tw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func limit[T any](s []T, n int) []T {
+	if n > 0 && n < len(s) {
+		return s[:n]
+	}
+	return s
+}
+
+// row writes one line of the table, its cells separated by tabs.
+func row(w io.Writer, cells ...any) {
+	for i, c := range cells {
+		if i > 0 {
+			fmt.Fprint(w, "\t")
+		}
+		fmt.Fprint(w, c)
+	}
+	fmt.Fprintln(w)
+}
+
+// deltaRow writes a diff row, with - for a package that a program does not
+// initialize.
+func deltaRow(w io.Writer, d inittrace.Delta) {
+	cell := func(in inittrace.Init, f func(inittrace.Init) string) string {
+		if in.Package == "" {
+			return "-"
+		}
+		return f(in)
+	}
+	clock := func(in inittrace.Init) string { return ms(in.Clock) }
+	bytes := func(in inittrace.Init) string { return fmt.Sprint(in.Bytes) }
+	allocs := func(in inittrace.Init) string { return fmt.Sprint(in.Allocs) }
+
+	row(w, d.Package,
+		cell(d.A, clock), cell(d.B, clock), signedMs(d.B.Clock-d.A.Clock),
+		cell(d.A, bytes), cell(d.B, bytes), signed(int64(d.B.Bytes)-int64(d.A.Bytes)),
+		cell(d.A, allocs), cell(d.B, allocs), signed(int64(d.B.Allocs)-int64(d.A.Allocs)))
+}
+
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%.3f", float64(d)/float64(time.Millisecond))
+}
+
+func signedMs(d time.Duration) string {
+	return fmt.Sprintf("%+.3f", float64(d)/float64(time.Millisecond))
+}
+
+func signed(n int64) string {
+	return fmt.Sprintf("%+d", n)
+}
@@ -0,0 +1,35 @@
+// Eager parses all of its pages' templates while its package is
+// initialized, as package-level variables, though a run renders only one.
+// Compare it with example/lazy using cmd/inittrace.
+package main
+
+import (
+	"html/template"
+	"os"
+	"strings"
+)
+
+var s = `
+<h1>{{.PageTitle}}<h1>
+<ul>
+    {{range .Todos}}
+        {{if .Done}}
+            <li class="done">{{.Title}}</li>
+        {{else}}
+            <li>{{.Title}}</li>
+        {{end}}
+    {{end}}
+</ul>
+`
+
+// costs time at load and maybe unused
+var pages = map[string]*template.Template{
+	"home":     template.Must(template.New("home").Parse(strings.Repeat(s, 10))),
+	"todos":    template.Must(template.New("todos").Parse(s)),
+	"archive":  template.Must(template.New("archive").Parse(strings.Repeat(s, 10))),
+	"settings": template.Must(template.New("settings").Parse(strings.Repeat(s, 10))),
+}
+
+func main() {
+	pages["todos"].Execute(os.Stdout, nil)
+}
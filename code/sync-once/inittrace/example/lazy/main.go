@@ -0,0 +1,40 @@
+// Lazy registers the same templates as example/eager, but parses only the
+// one it renders, on first use.
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/sathishvj/optimizing-go-programs/code/sync-once/templates"
+)
+
+var s = `
+<h1>{{.PageTitle}}<h1>
+<ul>
+    {{range .Todos}}
+        {{if .Done}}
+            <li class="done">{{.Title}}</li>
+        {{else}}
+            <li>{{.Title}}</li>
+        {{end}}
+    {{end}}
+</ul>
+`
+
+var pages = templates.New()
+
+func init() {
+	// cheap: the sources are only recorded
+	pages.Register("home", strings.Repeat(s, 10))
+	pages.Register("todos", s)
+	pages.Register("archive", strings.Repeat(s, 10))
+	pages.Register("settings", strings.Repeat(s, 10))
+}
+
+func main() {
+	if err := pages.Execute(os.Stdout, "todos", nil); err != nil {
+		log.Fatal(err)
+	}
+}
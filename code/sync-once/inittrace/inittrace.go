@@ -0,0 +1,259 @@
+// Package inittrace measures the start up cost of a program: it runs the
+// program with GODEBUG=inittrace=1 and parses the line the runtime prints
+// for each package it initializes,
+//
+//	init html/template @0.97 ms, 0.089 ms clock, 7792 bytes, 64 allocs
+//
+// that is, when the package's init started since the program did, how long
+// it took, and what it allocated. Only package-level variables and init
+// functions are counted; work done in main is not.
+package inittrace
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Init is the initialization of one package.
+type Init struct {
+	Package string
+	At      time.Duration // start, since the program started
+	Clock   time.Duration // wall time
+	Bytes   uint64        // allocated
+	Allocs  uint64
+}
+
+// Parse reads the init lines in r, in the order the runtime printed them.
+// Other lines are copied to other, if it is not nil.
+func Parse(r io.Reader, other io.Writer) ([]Init, error) {
+	var inits []Init
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		in, ok := parseLine(line)
+		if !ok {
+			if other != nil {
+				fmt.Fprintln(other, line)
+			}
+			continue
+		}
+		inits = append(inits, in)
+	}
+	return inits, sc.Err()
+}
+
+func parseLine(line string) (Init, bool) {
+	if !strings.HasPrefix(line, "init ") {
+		return Init{}, false
+	}
+	var in Init
+	var at, clock float64
+	_, err := fmt.Sscanf(line, "init %s @%g ms, %g ms clock, %d bytes, %d allocs",
+		&in.Package, &at, &clock, &in.Bytes, &in.Allocs)
+	if err != nil {
+		return Init{}, false
+	}
+	in.At = ms(at)
+	in.Clock = ms(clock)
+	return in, true
+}
+
+func ms(f float64) time.Duration {
+	return time.Duration(f * float64(time.Millisecond))
+}
+
+// Run runs the program count times, at least once, with init tracing on,
+// and returns the median of each package's figures. The program's output
+// is discarded, except for what it writes to stderr other than the init
+// lines, which is copied to stderr if that is not nil.
+func Run(ctx context.Context, count int, stderr io.Writer, name string, args ...string) ([]Init, error) {
+	godebug := "inittrace=1"
+	if v := os.Getenv("GODEBUG"); v != "" {
+		godebug = v + "," + godebug
+	}
+
+	count = max(count, 1)
+	runs := make([][]Init, 0, count)
+	for i := 0; i < count; i++ {
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Env = append(os.Environ(), "GODEBUG="+godebug)
+		out, err := cmd.StderrPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, err
+		}
+		inits, perr := Parse(out, stderr)
+		io.Copy(io.Discard, out) // the rest, if Parse stopped early, so the program can exit
+		if err := cmd.Wait(); err != nil {
+			return nil, fmt.Errorf("inittrace: %s: %w", name, err)
+		}
+		if perr != nil {
+			return nil, perr
+		}
+		if len(inits) == 0 {
+			return nil, fmt.Errorf("inittrace: %s printed no init lines; is it a Go program?", name)
+		}
+		runs = append(runs, inits)
+	}
+	return Median(runs), nil
+}
+
+// Median combines several runs of one program into one, taking the median
+// of each figure of each package. The packages are in the order of the
+// first run.
+func Median(runs [][]Init) []Init {
+	if len(runs) == 0 {
+		return nil
+	}
+	byPkg := make(map[string][]Init)
+	for _, run := range runs {
+		for _, in := range run {
+			byPkg[in.Package] = append(byPkg[in.Package], in)
+		}
+	}
+	inits := make([]Init, 0, len(runs[0]))
+	for _, first := range runs[0] {
+		all := byPkg[first.Package]
+		inits = append(inits, Init{
+			Package: first.Package,
+			At:      median(all, func(in Init) time.Duration { return in.At }),
+			Clock:   median(all, func(in Init) time.Duration { return in.Clock }),
+			Bytes:   median(all, func(in Init) uint64 { return in.Bytes }),
+			Allocs:  median(all, func(in Init) uint64 { return in.Allocs }),
+		})
+	}
+	return inits
+}
+
+func median[T cmp.Ordered](inits []Init, f func(Init) T) T {
+	vs := make([]T, len(inits))
+	for i, in := range inits {
+		vs[i] = f(in)
+	}
+	slices.Sort(vs)
+	return vs[len(vs)/2]
+}
+
+// Total sums the clock times, bytes and allocations of all the packages.
+// Its At is when the last package finished initializing, which is about
+// when main starts.
+func Total(inits []Init) Init {
+	t := Init{Package: "total"}
+	for _, in := range inits {
+		t.At = max(t.At, in.At+in.Clock)
+		t.Clock += in.Clock
+		t.Bytes += in.Bytes
+		t.Allocs += in.Allocs
+	}
+	return t
+}
+
+// Keys are the names Sort and SortDiff accept.
+var Keys = []string{"clock", "bytes", "allocs", "at", "package"}
+
+func compareBy(key string) (func(a, b Init) int, error) {
+	switch key {
+	case "clock":
+		return func(a, b Init) int { return cmp.Compare(b.Clock, a.Clock) }, nil
+	case "bytes":
+		return func(a, b Init) int { return cmp.Compare(b.Bytes, a.Bytes) }, nil
+	case "allocs":
+		return func(a, b Init) int { return cmp.Compare(b.Allocs, a.Allocs) }, nil
+	case "at":
+		return func(a, b Init) int { return cmp.Compare(a.At, b.At) }, nil
+	case "package":
+		return func(a, b Init) int { return strings.Compare(a.Package, b.Package) }, nil
+	}
+	return nil, fmt.Errorf("inittrace: unknown sort key %q, want one of %v", key, Keys)
+}
+
+// Sort sorts the packages by key: the largest clock, bytes or allocs
+// first, or by at or package in ascending order.
+func Sort(inits []Init, key string) error {
+	c, err := compareBy(key)
+	if err != nil {
+		return err
+	}
+	slices.SortStableFunc(inits, c)
+	return nil
+}
+
+// Delta is one package in two programs. A package that only one of them
+// initializes is zero in the other.
+type Delta struct {
+	Package string
+	A, B    Init
+}
+
+// Diff pairs up the packages of two programs.
+func Diff(a, b []Init) []Delta {
+	var deltas []Delta
+	index := make(map[string]int)
+	for _, in := range a {
+		index[in.Package] = len(deltas)
+		deltas = append(deltas, Delta{Package: in.Package, A: in})
+	}
+	for _, in := range b {
+		i, ok := index[in.Package]
+		if !ok {
+			i = len(deltas)
+			deltas = append(deltas, Delta{Package: in.Package})
+		}
+		deltas[i].B = in
+	}
+	return deltas
+}
+
+// SortDiff sorts the deltas by key, the largest change either way first,
+// or by at or package in ascending order. A package is at its place in the
+// first program, or in the second if only that initializes it.
+func SortDiff(deltas []Delta, key string) error {
+	if _, err := compareBy(key); err != nil {
+		return err
+	}
+	abs := func(d Delta) int64 {
+		switch key {
+		case "clock":
+			return absInt(int64(d.B.Clock - d.A.Clock))
+		case "bytes":
+			return absInt(int64(d.B.Bytes) - int64(d.A.Bytes))
+		case "allocs":
+			return absInt(int64(d.B.Allocs) - int64(d.A.Allocs))
+		}
+		return 0
+	}
+	slices.SortStableFunc(deltas, func(a, b Delta) int {
+		switch key {
+		case "at":
+			return cmp.Compare(a.at(), b.at())
+		case "package":
+			return strings.Compare(a.Package, b.Package)
+		}
+		return cmp.Compare(abs(b), abs(a))
+	})
+	return nil
+}
+
+func (d Delta) at() time.Duration {
+	if d.A.Package != "" {
+		return d.A.At
+	}
+	return d.B.At
+}
+
+func absInt(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
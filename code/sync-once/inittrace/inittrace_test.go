@@ -0,0 +1,113 @@
+package inittrace
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const trace = `init internal/bytealg @0.008 ms, 0 ms clock, 0 bytes, 0 allocs
+init runtime @0.10 ms, 0.12 ms clock, 0 bytes, 0 allocs
+hello from the program
+init html/template @1.7 ms, 0.13 ms clock, 7792 bytes, 64 allocs
+init main @2.5 ms, 1.5 ms clock, 104432 bytes, 2196 allocs
+init broken @x ms
+`
+
+func Test_Parse(t *testing.T) {
+	var other strings.Builder
+	got, err := Parse(strings.NewReader(trace), &other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []Init{
+		{"internal/bytealg", 8 * time.Microsecond, 0, 0, 0},
+		{"runtime", 100 * time.Microsecond, 120 * time.Microsecond, 0, 0},
+		{"html/template", 1700 * time.Microsecond, 130 * time.Microsecond, 7792, 64},
+		{"main", 2500 * time.Microsecond, 1500 * time.Microsecond, 104432, 2196},
+	}
+	if !slices.Equal(got, exp) {
+		t.Errorf("expected %v but got %v", exp, got)
+	}
+	if o := other.String(); o != "hello from the program\ninit broken @x ms\n" {
+		t.Errorf("expected the other lines copied but got %q", o)
+	}
+
+	exp2 := Init{"total", 4 * time.Millisecond, 1750 * time.Microsecond, 112224, 2260}
+	if total := Total(got); total != exp2 {
+		t.Errorf("expected %v but got %v", exp2, total)
+	}
+}
+
+func Test_Sort(t *testing.T) {
+	inits, _ := Parse(strings.NewReader(trace), nil)
+	if err := Sort(inits, "clock"); err != nil {
+		t.Fatal(err)
+	}
+	if inits[0].Package != "main" || inits[1].Package != "html/template" {
+		t.Errorf("expected the slowest first but got %v", inits)
+	}
+	if err := Sort(inits, "at"); err != nil {
+		t.Fatal(err)
+	}
+	if inits[0].Package != "internal/bytealg" {
+		t.Errorf("expected the first initialized first but got %v", inits)
+	}
+	if err := Sort(inits, "size"); err == nil {
+		t.Errorf("expected an error for an unknown key")
+	}
+}
+
+func Test_Median(t *testing.T) {
+	run := func(clock time.Duration, allocs uint64) []Init {
+		return []Init{{Package: "main", Clock: clock, Allocs: allocs}}
+	}
+	got := Median([][]Init{run(3, 10), run(1, 30), run(2, 20)})
+	if exp := run(2, 20); !slices.Equal(got, exp) {
+		t.Errorf("expected %v but got %v", exp, got)
+	}
+}
+
+func Test_Diff(t *testing.T) {
+	a := []Init{{Package: "html/template", Clock: 100}, {Package: "main", Clock: 1000, Bytes: 5000}}
+	b := []Init{{Package: "html/template", Clock: 100}, {Package: "lazy", Clock: 10, Bytes: 10}, {Package: "main", Clock: 50, Bytes: 500}}
+	deltas := Diff(a, b)
+	if err := SortDiff(deltas, "bytes"); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []Delta{
+		{"main", a[1], b[2]},
+		{"lazy", Init{}, b[1]},
+		{"html/template", a[0], b[0]},
+	}
+	if !slices.Equal(deltas, exp) {
+		t.Errorf("expected %v but got %v", exp, deltas)
+	}
+}
+
+// Test_Run traces this test binary, run again with no tests.
+func Test_Run(t *testing.T) {
+	var other strings.Builder
+	inits, err := Run(context.Background(), 3, &other, os.Args[0], "-test.run=^$")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := slices.IndexFunc(inits, func(in Init) bool { return in.Package == "testing" })
+	if i < 0 {
+		t.Fatalf("expected package testing in %v", inits)
+	}
+	if strings.Contains(other.String(), "init ") {
+		t.Errorf("expected no init lines in the other output but got %q", other.String())
+	}
+
+	if inits, err := Run(context.Background(), -1, nil, os.Args[0], "-test.run=^$"); err != nil || len(inits) == 0 {
+		t.Errorf("expected a count below 1 to run once but got %d packages, %v", len(inits), err)
+	}
+	if _, err := Run(context.Background(), 1, nil, os.Args[0], "-test.badflag"); err == nil {
+		t.Errorf("expected an error from a failing program")
+	}
+}
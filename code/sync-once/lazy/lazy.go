@@ -0,0 +1,118 @@
+// Package lazy initializes a value on first use, like sync.Once, but lets
+// the initializer fail. A failure is returned to every caller waiting on it
+// and retried on a later call, optionally after a backoff, and a success is
+// cached for good.
+//
+//	var home = lazy.New(func() (*template.Template, error) {
+//		return template.ParseFiles("home.html")
+//	}, lazy.WithBackoff(100*time.Millisecond, 10*time.Second))
+//
+//	t, err := home.Get()
+//
+// Once the value is there, Get is a single atomic load.
+package lazy
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Lazy is a value of type T initialized on the first successful Get. It is
+// safe for concurrent use.
+type Lazy[T any] struct {
+	value atomic.Pointer[T] // set once init has succeeded
+
+	mu       sync.Mutex
+	init     func() (T, error) // nil after it has succeeded
+	attempts atomic.Uint64     // finished calls of init
+	err      error             // of the last attempt
+	failures int               // in a row
+	retryAt  time.Time         // no attempt before this, with a backoff
+
+	minBackoff, maxBackoff time.Duration
+	now                    func() time.Time
+	loaded                 func() // test hook, called once slow has read attempts
+}
+
+// Option configures a Lazy.
+type Option func(*config)
+
+type config struct {
+	minBackoff, maxBackoff time.Duration
+}
+
+// WithBackoff makes Get return the last error without calling init again
+// until a backoff has passed: min after the first failure, doubling with
+// every failure after that, up to max. Without it, every Get after a
+// failure tries again.
+func WithBackoff(min, max time.Duration) Option {
+	return func(c *config) {
+		c.minBackoff, c.maxBackoff = min, max
+	}
+}
+
+// New returns a Lazy that is initialized by init.
+func New[T any](init func() (T, error), opts ...Option) *Lazy[T] {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &Lazy[T]{
+		init:       init,
+		minBackoff: c.minBackoff,
+		maxBackoff: max(c.minBackoff, c.maxBackoff),
+		now:        time.Now,
+	}
+}
+
+// Get returns the value, initializing it if no call has done so yet. If
+// init fails, Get returns its error, and so do the calls that were waiting
+// for that attempt. A later call tries again, once the backoff is over.
+func (l *Lazy[T]) Get() (T, error) {
+	if v := l.value.Load(); v != nil {
+		return *v, nil
+	}
+	return l.slow()
+}
+
+func (l *Lazy[T]) slow() (T, error) {
+	attempts := l.attempts.Load()
+	if l.loaded != nil {
+		l.loaded()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if v := l.value.Load(); v != nil {
+		return *v, nil
+	}
+	var zero T
+	// Another call's attempt failed while this one waited for the lock:
+	// share its error instead of starting a burst of retries.
+	if l.attempts.Load() != attempts {
+		return zero, l.err
+	}
+	if l.err != nil && l.now().Before(l.retryAt) {
+		return zero, l.err
+	}
+
+	v, err := l.init()
+	l.attempts.Add(1)
+	if err != nil {
+		l.err = err
+		l.failures++
+		if l.minBackoff > 0 {
+			backoff := l.minBackoff
+			for i := 1; i < l.failures && backoff < l.maxBackoff; i++ {
+				backoff *= 2
+			}
+			l.retryAt = l.now().Add(min(backoff, l.maxBackoff))
+		}
+		return zero, err
+	}
+
+	l.value.Store(&v)
+	l.init, l.err = nil, nil
+	return v, nil
+}
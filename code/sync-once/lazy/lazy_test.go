@@ -0,0 +1,200 @@
+package lazy
+
+import (
+	"errors"
+	"html/template"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Lazy(t *testing.T) {
+	var calls atomic.Int32
+	l := New(func() (int, error) {
+		calls.Add(1)
+		return 42, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := l.Get(); v != 42 || err != nil {
+				t.Errorf("got %d, %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected init to run once but it ran %d times", n)
+	}
+}
+
+func Test_Lazy_Retry(t *testing.T) {
+	errBad := errors.New("bad template")
+	fail := true
+	l := New(func() (string, error) {
+		if fail {
+			return "", errBad
+		}
+		return "ok", nil
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := l.Get(); err != errBad {
+			t.Errorf("expected the init error but got %v", err)
+		}
+	}
+	fail = false
+	if v, err := l.Get(); v != "ok" || err != nil {
+		t.Errorf("expected a retry to succeed but got %q, %v", v, err)
+	}
+	fail = true
+	if v, err := l.Get(); v != "ok" || err != nil {
+		t.Errorf("expected the value cached but got %q, %v", v, err)
+	}
+}
+
+func Test_Lazy_Backoff(t *testing.T) {
+	var calls int
+	l := New(func() (int, error) {
+		calls++
+		return 0, errors.New("down")
+	}, WithBackoff(time.Second, 3*time.Second))
+	now := time.Unix(0, 0)
+	l.now = func() time.Time { return now }
+
+	// the attempts happen at 0s, 1s, 3s, 6s and 9s: the backoff doubles
+	// from 1s and stops at 3s
+	tests := []struct {
+		at    time.Duration
+		calls int
+	}{
+		{0, 1}, {999 * time.Millisecond, 1}, {time.Second, 2}, {2 * time.Second, 2},
+		{3 * time.Second, 3}, {5 * time.Second, 3}, {6 * time.Second, 4}, {9 * time.Second, 5},
+	}
+	for _, test := range tests {
+		now = time.Unix(0, 0).Add(test.at)
+		if _, err := l.Get(); err == nil {
+			t.Fatalf("expected an error")
+		}
+		if calls != test.calls {
+			t.Errorf("at %v: expected %d attempts but got %d", test.at, test.calls, calls)
+		}
+	}
+}
+
+// Test_Lazy_SharedFailure checks that the calls waiting on a failing
+// attempt get its error, rather than each making an attempt of its own.
+func Test_Lazy_SharedFailure(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var calls atomic.Int32
+	l := New(func() (int, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		return 0, errors.New("down")
+	})
+	loaded := make(chan struct{}, 11)
+	l.loaded = func() { loaded <- struct{}{} }
+
+	go l.Get()
+	<-started
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := l.Get(); err == nil {
+				t.Errorf("expected an error")
+			}
+		}()
+	}
+	// wait until every call has read the attempt count, the first included,
+	// so that none of them can miss the failure
+	for i := 0; i < 11; i++ {
+		<-loaded
+	}
+	close(release)
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected the waiters to share one attempt but init ran %d times", n)
+	}
+}
+
+// The benchmarks compare the ways code/sync-once gets its template.
+
+var s = `
+<h1>{{.PageTitle}}<h1>
+<ul>
+    {{range .Todos}}
+        {{if .Done}}
+            <li class="done">{{.Title}}</li>
+        {{else}}
+            <li>{{.Title}}</li>
+        {{end}}
+    {{end}}
+</ul>
+`
+
+var sink *template.Template
+
+func parse() (*template.Template, error) {
+	return template.New("").Parse(s)
+}
+
+// 1.go: parse on every call
+func Benchmark_PerCall(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		sink = template.Must(parse())
+	}
+}
+
+// 2.go: parse at start up
+func Benchmark_Eager(b *testing.B) {
+	t := template.Must(parse())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sink = t
+	}
+}
+
+// 3.go: sync.Once
+func Benchmark_Once(b *testing.B) {
+	var t *template.Template
+	var o sync.Once
+	for i := 0; i < b.N; i++ {
+		o.Do(func() { t = template.Must(parse()) })
+		sink = t
+	}
+}
+
+func Benchmark_Lazy(b *testing.B) {
+	l := New(parse)
+	for i := 0; i < b.N; i++ {
+		sink, _ = l.Get()
+	}
+}
+
+func Benchmark_Once_Parallel(b *testing.B) {
+	var t *template.Template
+	var o sync.Once
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			o.Do(func() { t = template.Must(parse()) })
+			_ = t
+		}
+	})
+}
+
+func Benchmark_Lazy_Parallel(b *testing.B) {
+	l := New(parse)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Get()
+		}
+	})
+}
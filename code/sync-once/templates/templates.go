@@ -0,0 +1,231 @@
+// Package templates is a registry of html templates that are parsed on
+// first use instead of at start up. Registering a template only records its
+// source; the first Execute parses it, once, however many requests arrive
+// together.
+//
+//	r := templates.New()
+//	r.Register("todos", s)
+//	r.RegisterFS("home", os.DirFS("web"), "home.html", "partials/*.html")
+//	err := r.Execute(w, "todos", data)
+//
+// In development, Watch polls the files of the fs.FS templates and reparses
+// a template on the next Execute after one of its files changes.
+package templates
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sathishvj/optimizing-go-programs/code/sync-once/lazy"
+)
+
+// Registry holds templates by name. It is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]*entry
+}
+
+type entry struct {
+	name     string
+	src      string
+	fsys     fs.FS
+	patterns []string
+
+	tmpl atomic.Pointer[lazy.Lazy[*template.Template]] // replaced on a change
+
+	compiles, failures, invalidations atomic.Uint64
+	total, last                       atomic.Int64 // compile time, ns
+}
+
+// New returns an empty Registry.
+func New() *Registry {
+	return &Registry{entries: make(map[string]*entry)}
+}
+
+// Register adds a template parsed from src.
+func (r *Registry) Register(name, src string) error {
+	return r.add(&entry{name: name, src: src})
+}
+
+// RegisterFS adds a template parsed from the files in fsys that match the
+// patterns, as template.ParseFS does. Executing it runs the template of the
+// first file.
+func (r *Registry) RegisterFS(name string, fsys fs.FS, patterns ...string) error {
+	if len(patterns) == 0 {
+		return fmt.Errorf("templates: no patterns for %q", name)
+	}
+	return r.add(&entry{name: name, fsys: fsys, patterns: patterns})
+}
+
+func (r *Registry) add(e *entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entries[e.name]; ok {
+		return fmt.Errorf("templates: %q registered twice", e.name)
+	}
+	e.reset()
+	r.entries[e.name] = e
+	return nil
+}
+
+// Execute applies the named template to data and writes the output to w,
+// parsing the template first if this is its first use.
+func (r *Registry) Execute(w io.Writer, name string, data any) error {
+	t, err := r.Lookup(name)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, data)
+}
+
+// Lookup returns the named template, parsing it if this is its first use.
+// A parse error is returned to every call waiting on that parse, and the
+// next call tries again.
+func (r *Registry) Lookup(name string) (*template.Template, error) {
+	r.mu.RLock()
+	e, ok := r.entries[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("templates: no template %q", name)
+	}
+	return e.tmpl.Load().Get()
+}
+
+// Invalidate drops the parsed template, so that the next use parses it
+// again. Executions already running finish with the old one.
+func (r *Registry) Invalidate(name string) {
+	r.mu.RLock()
+	e, ok := r.entries[name]
+	r.mu.RUnlock()
+	if ok {
+		e.invalidations.Add(1)
+		e.reset()
+	}
+}
+
+func (e *entry) reset() {
+	e.tmpl.Store(lazy.New(e.parse))
+}
+
+func (e *entry) parse() (*template.Template, error) {
+	start := time.Now()
+	var t *template.Template
+	var err error
+	if e.fsys != nil {
+		t, err = template.ParseFS(e.fsys, e.patterns...)
+	} else {
+		t, err = template.New(e.name).Parse(e.src)
+	}
+	d := time.Since(start)
+
+	e.total.Add(int64(d))
+	e.last.Store(int64(d))
+	if err != nil {
+		e.failures.Add(1)
+		return nil, err
+	}
+	e.compiles.Add(1)
+	return t, nil
+}
+
+// Watch checks the files of every RegisterFS template each interval, and
+// invalidates a template when one of its files is added, removed or
+// modified. It returns when ctx is done. It is meant for development, where
+// the files are edited while the program runs.
+//
+// The files are first looked at when Watch starts, or for a template
+// registered later, on the first check after that; nothing is read at
+// registration. A change made before that first look is not noticed.
+func (r *Registry) Watch(ctx context.Context, interval time.Duration) {
+	seen := make(map[*entry]string) // the files as of the last check
+	check := func() {
+		r.mu.RLock()
+		entries := make([]*entry, 0, len(r.entries))
+		for _, e := range r.entries {
+			if e.fsys != nil {
+				entries = append(entries, e)
+			}
+		}
+		r.mu.RUnlock()
+
+		for _, e := range entries {
+			m := e.stat()
+			if last, ok := seen[e]; ok && m != last {
+				e.invalidations.Add(1)
+				e.reset()
+			}
+			seen[e] = m
+		}
+	}
+
+	check()
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			check()
+		}
+	}
+}
+
+// stat returns the names, sizes and modification times of the files the
+// template is parsed from, as one string to compare.
+func (e *entry) stat() string {
+	var b strings.Builder
+	for _, p := range e.patterns {
+		names, _ := fs.Glob(e.fsys, p)
+		for _, n := range names {
+			fi, err := fs.Stat(e.fsys, n)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(&b, "%s %d %d\n", n, fi.Size(), fi.ModTime().UnixNano())
+		}
+	}
+	return b.String()
+}
+
+// Stats counts the parses of one template.
+type Stats struct {
+	Name          string
+	Compiles      uint64        // successful parses
+	Failures      uint64        // parses that returned an error
+	Invalidations uint64        // by Invalidate or Watch
+	CompileTime   time.Duration // of all the parses
+	LastCompile   time.Duration // of the latest parse
+}
+
+// Stats returns the parse counts of every template, sorted by name.
+func (r *Registry) Stats() []Stats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stats := make([]Stats, 0, len(r.entries))
+	for _, e := range r.entries {
+		stats = append(stats, Stats{
+			Name:          e.name,
+			Compiles:      e.compiles.Load(),
+			Failures:      e.failures.Load(),
+			Invalidations: e.invalidations.Load(),
+			CompileTime:   time.Duration(e.total.Load()),
+			LastCompile:   time.Duration(e.last.Load()),
+		})
+	}
+	slices.SortFunc(stats, func(a, b Stats) int { return strings.Compare(a.Name, b.Name) })
+	return stats
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%s: %d compiles, %d failures, %d invalidations, %v total, %v last",
+		s.Name, s.Compiles, s.Failures, s.Invalidations, s.CompileTime, s.LastCompile)
+}
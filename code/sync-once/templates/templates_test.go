@@ -0,0 +1,220 @@
+package templates
+
+import (
+	"context"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var s = `
+<h1>{{.PageTitle}}<h1>
+<ul>
+    {{range .Todos}}
+        {{if .Done}}
+            <li class="done">{{.Title}}</li>
+        {{else}}
+            <li>{{.Title}}</li>
+        {{end}}
+    {{end}}
+</ul>
+`
+
+type todo struct {
+	Title string
+	Done  bool
+}
+
+var data = struct {
+	PageTitle string
+	Todos     []todo
+}{"list", []todo{{"a", true}, {"b", false}}}
+
+func execute(t *testing.T, r *Registry, name string) string {
+	t.Helper()
+	var b strings.Builder
+	if err := r.Execute(&b, name, data); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func Test_Registry(t *testing.T) {
+	r := New()
+	if err := r.Register("todos", s); err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"home.html":       {Data: []byte(`home {{template "footer" .}}`)},
+		"partials/f.html": {Data: []byte(`{{define "footer"}}of {{.PageTitle}}{{end}}`)},
+	}
+	if err := r.RegisterFS("home", fsys, "home.html", "partials/*.html"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := execute(t, r, "todos"); !strings.Contains(got, `<li class="done">a</li>`) {
+		t.Errorf("expected the todos rendered but got %q", got)
+	}
+	if got := execute(t, r, "home"); got != "home of list" {
+		t.Errorf("expected %q but got %q", "home of list", got)
+	}
+	if err := r.Execute(io.Discard, "missing", nil); err == nil {
+		t.Errorf("expected an error for an unregistered template")
+	}
+	if err := r.Register("todos", s); err == nil {
+		t.Errorf("expected an error for a second registration")
+	}
+}
+
+func Test_Registry_OneParse(t *testing.T) {
+	r := New()
+	r.Register("todos", s)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := r.Execute(io.Discard, "todos", data); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if st := r.Stats()[0]; st.Compiles != 1 || st.CompileTime <= 0 {
+		t.Errorf("expected one timed parse but got %v", st)
+	}
+}
+
+func Test_Registry_Invalidate(t *testing.T) {
+	fsys := fstest.MapFS{"t.html": {Data: []byte(`{{.PageTitle`)}}
+	r := New()
+	r.RegisterFS("t", fsys, "t.html")
+
+	// a parse error is returned, and retried on the next call
+	for i := 0; i < 2; i++ {
+		if _, err := r.Lookup("t"); err == nil {
+			t.Errorf("expected a parse error")
+		}
+	}
+	fsys["t.html"] = &fstest.MapFile{Data: []byte(`v1 {{.PageTitle}}`)}
+	if got := execute(t, r, "t"); got != "v1 list" {
+		t.Errorf("expected %q but got %q", "v1 list", got)
+	}
+
+	// a parsed template is kept until it is invalidated
+	fsys["t.html"] = &fstest.MapFile{Data: []byte(`v2 {{.PageTitle}}`)}
+	if got := execute(t, r, "t"); got != "v1 list" {
+		t.Errorf("expected %q but got %q", "v1 list", got)
+	}
+	r.Invalidate("t")
+	if got := execute(t, r, "t"); got != "v2 list" {
+		t.Errorf("expected %q but got %q", "v2 list", got)
+	}
+
+	exp := Stats{Name: "t", Compiles: 2, Failures: 2, Invalidations: 1}
+	got := r.Stats()[0]
+	got.CompileTime, got.LastCompile = 0, 0
+	if got != exp {
+		t.Errorf("expected %v but got %v", exp, got)
+	}
+}
+
+// openCounter counts the files opened in an fs.FS.
+type openCounter struct {
+	fs.FS
+	opens atomic.Int32
+}
+
+func (c *openCounter) Open(name string) (fs.File, error) {
+	c.opens.Add(1)
+	return c.FS.Open(name)
+}
+
+func Test_Registry_RegisterFS_NoIO(t *testing.T) {
+	fsys := &openCounter{FS: fstest.MapFS{"t.html": {Data: []byte(`{{.PageTitle}}`)}}}
+	r := New()
+	r.RegisterFS("t", fsys, "t.html", "*.html")
+	if n := fsys.opens.Load(); n != 0 {
+		t.Errorf("expected RegisterFS to read nothing but it opened %d files", n)
+	}
+	if got := execute(t, r, "t"); got != "list" || fsys.opens.Load() == 0 {
+		t.Errorf("expected the first Execute to read the files but got %q", got)
+	}
+}
+
+func Test_Registry_Watch(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "t.html")
+	write := func(src string, mod time.Time) {
+		if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// the same size, so only the time tells the versions apart
+		if err := os.Chtimes(file, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`v1 {{.PageTitle}}`, time.Unix(1000, 0))
+
+	r := New()
+	r.RegisterFS("t", os.DirFS(dir), "t.html")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Watch(ctx, time.Millisecond)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	if got := execute(t, r, "t"); got != "v1 list" {
+		t.Errorf("expected %q but got %q", "v1 list", got)
+	}
+	// Watch takes its first look at the files in its own time, so keep
+	// editing the file until it notices.
+	deadline := time.Now().Add(5 * time.Second)
+	for i := 0; execute(t, r, "t") != "v2 list"; i++ {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected Watch to pick up the change")
+		}
+		write(`v2 {{.PageTitle}}`, time.Unix(int64(2000+i), 0))
+		time.Sleep(time.Millisecond)
+	}
+	if st := r.Stats()[0]; st.Compiles < 2 || st.Invalidations < 1 {
+		t.Errorf("expected an invalidation but got %v", st)
+	}
+}
+
+// Benchmark_Registry is the cost of the lookup over executing a template
+// held in a variable, as 2.go and 3.go do.
+func Benchmark_Registry(b *testing.B) {
+	r := New()
+	r.Register("todos", s)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			r.Execute(io.Discard, "todos", data)
+		}
+	})
+}
+
+func Benchmark_Variable(b *testing.B) {
+	t := template.Must(template.New("").Parse(s))
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			t.Execute(io.Discard, data)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+// run: go test -bench=Mixed -benchmem
+// study: allocations and retained-B, the heap a pool still holds after the
+// run, for a workload of mostly 1KiB and a few 4MiB buffers.
+// expected: the plain sync.Pool allocates nothing, but keeps a 4MiB buffer
+// around to serve 1KiB requests. The capped pool allocates the rare 4MiB
+// buffers afresh and retains only small ones.
+package main
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/sathishvj/optimizing-go-programs/code/sync.pool/pool"
+)
+
+var payload = make([]byte, 4<<20)
+
+// mixedSize is 1KiB for 63 out of 64 requests and 4MiB for the rest.
+func mixedSize(i int) int {
+	if i%64 == 63 {
+		return 4 << 20
+	}
+	return 1 << 10
+}
+
+func benchmarkMixed(b *testing.B, get func(size int) *bytes.Buffer, put func(*bytes.Buffer)) {
+	// Two GCs empty every pool, including its victim cache.
+	runtime.GC()
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		size := mixedSize(i)
+		buf := get(size)
+		buf.Write(payload[:size])
+		put(buf)
+	}
+	b.StopTimer()
+
+	// After one GC the pool's contents are still reachable from its victim
+	// cache, and are what the pool would hand out next.
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	b.ReportMetric(float64(int64(after.HeapAlloc)-int64(before.HeapAlloc)), "retained-B")
+}
+
+func Benchmark_Mixed_alloc(b *testing.B) {
+	benchmarkMixed(b,
+		func(size int) *bytes.Buffer { return bytes.NewBuffer(make([]byte, 0, size)) },
+		func(*bytes.Buffer) {},
+	)
+}
+
+var mixedPool = sync.Pool{
+	New: func() interface{} {
+		return &bytes.Buffer{}
+	},
+}
+
+func Benchmark_Mixed_syncPool(b *testing.B) {
+	benchmarkMixed(b,
+		func(int) *bytes.Buffer { return mixedPool.Get().(*bytes.Buffer) },
+		func(buf *bytes.Buffer) {
+			buf.Reset()
+			mixedPool.Put(buf)
+		},
+	)
+}
+
+var mixedBuffers = pool.NewBufferPool(64 << 10)
+
+func Benchmark_Mixed_bufferPool(b *testing.B) {
+	benchmarkMixed(b, mixedBuffers.Get, mixedBuffers.Put)
+}
@@ -0,0 +1,161 @@
+// run: go test -bench=pressure -benchmem
+// study: allocs/op and news/op, the rate at which the pool had to call New,
+// for f1-f3, write1, write2 and write2Pooled run
+//   - quiet: as in the other benchmarks, one goroutine and no GC to speak of
+//   - gc: with runtime.GC() forced every millisecond
+//   - churn: with a background goroutine allocating 256KiB every 100µs
+//
+// each serial and with b.RunParallel.
+// expected: pools are per P, and every GC moves their contents to a victim
+// cache that the next GC drops. A GC every millisecond still leaves thousands
+// of Gets in between, so news/op stays near 0 here. The pool stops paying
+// off when a P Gets only a few times per GC cycle, and then news/op
+// approaches 1.
+package main
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sathishvj/optimizing-go-programs/code/sync.pool/pool"
+)
+
+const churnSize = 256 << 10
+
+var churnSink []byte
+
+// startPressure starts the background load of the given mode. stop ends it
+// and returns what the load itself allocated, so the benchmark can leave
+// that out of its per-op numbers.
+func startPressure(mode string) (stop func() (mallocs, bytes uint64)) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	var mallocs, bytes uint64
+
+	switch mode {
+	case "gc":
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t := time.NewTicker(time.Millisecond)
+			defer t.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-t.C:
+					runtime.GC()
+				}
+			}
+		}()
+	case "churn":
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				churnSink = make([]byte, churnSize)
+				mallocs++
+				bytes += churnSize
+				time.Sleep(100 * time.Microsecond)
+			}
+		}()
+	}
+
+	return func() (uint64, uint64) {
+		close(done)
+		wg.Wait()
+		return mallocs, bytes
+	}
+}
+
+// benchmarkPressure runs op in every mode, serially and in parallel. stats
+// is nil for the versions without a pool.
+func benchmarkPressure(b *testing.B, stats func() pool.Stats, op func()) {
+	for _, mode := range []string{"quiet", "gc", "churn"} {
+		for _, parallel := range []bool{false, true} {
+			name := mode + "/serial"
+			if parallel {
+				name = mode + "/parallel"
+			}
+
+			b.Run(name, func(b *testing.B) {
+				var s pool.Stats
+				if stats != nil {
+					s = stats()
+				}
+				var before, after runtime.MemStats
+				runtime.ReadMemStats(&before)
+				stop := startPressure(mode)
+
+				b.ResetTimer()
+				if parallel {
+					b.RunParallel(func(pb *testing.PB) {
+						for pb.Next() {
+							op()
+						}
+					})
+				} else {
+					for i := 0; i < b.N; i++ {
+						op()
+					}
+				}
+				b.StopTimer()
+
+				churnMallocs, churnBytes := stop()
+				runtime.ReadMemStats(&after)
+				n := float64(b.N)
+				// These replace the numbers -benchmem would report, which
+				// would include the background load.
+				b.ReportMetric(float64(after.Mallocs-before.Mallocs-churnMallocs)/n, "allocs/op")
+				b.ReportMetric(float64(after.TotalAlloc-before.TotalAlloc-churnBytes)/n, "B/op")
+				if stats != nil {
+					b.ReportMetric(float64(stats().Sub(s).News)/n, "news/op")
+				}
+			})
+		}
+	}
+}
+
+// countNews counts the New calls of a plain sync.Pool, as WithStats does
+// for a pool.Pool, until restore puts its New back.
+func countNews(p *sync.Pool) (stats func() pool.Stats, restore func()) {
+	var news atomic.Uint64
+	orig := p.New
+	p.New = func() any {
+		news.Add(1)
+		return orig()
+	}
+	return func() pool.Stats { return pool.Stats{News: news.Load()} }, func() { p.New = orig }
+}
+
+func Benchmark_f1_pressure(b *testing.B) { benchmarkPressure(b, nil, f1) }
+
+func Benchmark_f2_pressure(b *testing.B) {
+	stats, restore := countNews(&pool2)
+	defer restore()
+	benchmarkPressure(b, stats, f2)
+}
+
+func Benchmark_f3_pressure(b *testing.B) { benchmarkPressure(b, pool3.Stats, f3) }
+
+func Benchmark_write1_pressure(b *testing.B) {
+	benchmarkPressure(b, nil, func() { write1("harry", "rowling") })
+}
+
+func Benchmark_write2_pressure(b *testing.B) {
+	stats, restore := countNews(&bookPool)
+	defer restore()
+	benchmarkPressure(b, stats, func() { write2("harry", "rowling") })
+}
+
+func Benchmark_write2Pooled_pressure(b *testing.B) {
+	benchmarkPressure(b, bookPool2.Stats, func() { write2Pooled("harry", "rowling") })
+}
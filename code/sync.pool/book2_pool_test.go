@@ -0,0 +1,39 @@
+// run: go test -bench=write2 -benchmem
+// study: write2Pooled does what write2 does with a pool.Pool, which clears
+// each book before pooling it and counts how many Gets it served.
+// expected: the same allocations as write2, and a reuse ratio of 1.
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sathishvj/optimizing-go-programs/code/sync.pool/pool"
+)
+
+// Put clears a book before pooling it, so the next Get never sees the
+// previous author and title.
+var bookPool2 = pool.New(
+	func() *Book2 { return &Book2{} },
+	func(b *Book2) { *b = Book2{} },
+	pool.WithStats(),
+)
+
+func write2Pooled(a, t string) {
+	b := bookPool2.Get()
+	b.Author = a
+	b.Title = t
+	b.ISBN = "abcd"
+	data, _ := json.Marshal(b)
+	_ = data
+
+	bookPool2.Put(b)
+}
+
+func Benchmark_write2Pooled(b *testing.B) {
+	before := bookPool2.Stats()
+	for i := 0; i < b.N; i++ {
+		write2Pooled("harry", "rowling")
+	}
+	b.ReportMetric(bookPool2.Stats().Sub(before).ReuseRatio(), "reuse")
+}
@@ -0,0 +1,141 @@
+// run: go test -bench=write -benchmem
+// study: write2 and write2Pooled pool the Book, but json.Marshal still allocates its output
+// and encoder state on every call. write3 appends the JSON to a pooled byte
+// slice instead.
+// expected: write3 should have no allocations at all.
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/sathishvj/optimizing-go-programs/code/sync.pool/pool"
+)
+
+// AppendJSON appends the JSON encoding of b to dst and returns the extended
+// slice, byte for byte what json.Marshal(b) returns.
+func AppendJSON(dst []byte, b *Book) []byte {
+	dst = append(dst, `{"Author":`...)
+	dst = appendString(dst, b.Author)
+	dst = append(dst, `,"Title":`...)
+	dst = appendString(dst, b.Title)
+	dst = append(dst, `,"ISBN":`...)
+	dst = appendString(dst, b.ISBN)
+	return append(dst, '}')
+}
+
+const hex = "0123456789abcdef"
+
+// appendString appends s as a JSON string, escaped as encoding/json does:
+// HTML-safe, with invalid UTF-8 replaced by U+FFFD.
+func appendString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+		case r == '\u2028' || r == '\u2029':
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[r&0xf])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
+
+// jsonBuffers holds the output slices. A slice is only valid until it is
+// Put back.
+var jsonBuffers = pool.New(
+	func() *[]byte {
+		b := make([]byte, 0, 256)
+		return &b
+	},
+	func(b *[]byte) { *b = (*b)[:0] },
+)
+
+func write3(a, t string) {
+	b := Book{Author: a, Title: t, ISBN: "abcd"}
+	buf := jsonBuffers.Get()
+	*buf = AppendJSON(*buf, &b)
+	data := *buf
+	_ = data
+
+	jsonBuffers.Put(buf)
+}
+
+func Benchmark_write3(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		write3("harry", "rowling")
+	}
+}
+
+var jsonStrings = []string{
+	"", "harry", `"quoted" \ back\slash`, "<b>&amp;</b>", "tab\tnew\nline\r",
+	"\b\f\x00\x1f\x7f", "héllo, 世界 🐹", "\u2028\u2029", "bad \xff\xfe utf-8", "\xed\xa0\x80",
+	strings.Repeat("long ", 100),
+}
+
+func Test_AppendJSON(t *testing.T) {
+	for _, s := range jsonStrings {
+		checkAppendJSON(t, &Book{Author: s, Title: s + "!", ISBN: "abcd"})
+	}
+}
+
+func Fuzz_AppendJSON(f *testing.F) {
+	for _, s := range jsonStrings {
+		f.Add(s, s)
+	}
+	f.Fuzz(func(t *testing.T, author, title string) {
+		checkAppendJSON(t, &Book{Author: author, Title: title, ISBN: "abcd"})
+	})
+}
+
+func checkAppendJSON(t *testing.T, b *Book) {
+	t.Helper()
+	exp, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := AppendJSON([]byte("prefix"), b)
+	if string(got) != "prefix"+string(exp) {
+		t.Errorf("for %+q\nexpected %s\n     got %s", *b, exp, got[len("prefix"):])
+	}
+}
@@ -0,0 +1,116 @@
+package pool
+
+import (
+	"bytes"
+	"math/bits"
+	"sync/atomic"
+)
+
+// minClassBits is log2 of the smallest size class, 64 bytes.
+const minClassBits = 6
+
+// BufferPool is a pool of *bytes.Buffer in power-of-two size classes.
+//
+// A single sync.Pool of buffers hands a 1KiB request the 8MiB buffer that
+// an earlier large request grew, and keeps that 8MiB alive for as long as
+// the pool is busy. BufferPool instead files every buffer under the largest
+// class its capacity covers, serves Get from the smallest class that covers
+// the size hint, and drops any buffer that has grown past its cap.
+type BufferPool struct {
+	classes []*Pool[*bytes.Buffer]
+	max     int
+	stats   *bufferCounters // nil unless WithStats
+}
+
+type bufferCounters struct {
+	oversized, dropped atomic.Uint64
+}
+
+// NewBufferPool returns a pool that retains buffers of up to limit bytes of
+// capacity, with limit rounded up to a power of two. The options apply to
+// every size class.
+func NewBufferPool(limit int, opts ...Option) *BufferPool {
+	p := &BufferPool{max: 1 << max(bits.Len(uint(max(limit, 1)-1)), minClassBits)}
+	for size := 1 << minClassBits; size <= p.max; size *= 2 {
+		p.classes = append(p.classes, New(
+			func() *bytes.Buffer { return bytes.NewBuffer(make([]byte, 0, size)) },
+			(*bytes.Buffer).Reset,
+			append(opts[:len(opts):len(opts)], WithPoison(PoisonBuffer))...,
+		))
+	}
+	if p.classes[0].stats != nil {
+		p.stats = &bufferCounters{}
+	}
+	return p
+}
+
+// Get returns an empty buffer with a capacity of at least sizeHint. A hint
+// above the cap gets a new buffer that Put will not retain.
+func (p *BufferPool) Get(sizeHint int) *bytes.Buffer {
+	if sizeHint > p.max {
+		if p.stats != nil {
+			p.stats.oversized.Add(1)
+		}
+		return bytes.NewBuffer(make([]byte, 0, sizeHint))
+	}
+	// the smallest class of at least sizeHint bytes
+	class := max(bits.Len(uint(max(sizeHint, 1)-1)), minClassBits) - minClassBits
+	return p.classes[class].Get()
+}
+
+// Put resets b and returns it to the pool, unless its capacity is above
+// the cap, or below the smallest class. b must not be used afterwards.
+func (p *BufferPool) Put(b *bytes.Buffer) {
+	c := b.Cap()
+	if c > p.max || c < 1<<minClassBits {
+		if p.stats != nil {
+			p.stats.dropped.Add(1)
+		}
+		return
+	}
+	// the largest class of at most c bytes
+	p.classes[bits.Len(uint(c))-1-minClassBits].Put(b)
+}
+
+// PoisonBuffer fills the spare capacity of b, which is all of it after a
+// Reset, with 0xdeadbeef. A BufferPool poisons its buffers with it in
+// -tags pooldebug builds, so a slice from Bytes kept past Put reads garbage.
+func PoisonBuffer(b *bytes.Buffer) {
+	spare := b.AvailableBuffer()
+	spare = spare[:cap(spare)]
+	for i := range spare {
+		spare[i] = "\xde\xad\xbe\xef"[i%4]
+	}
+}
+
+// Max returns the largest capacity the pool retains.
+func (p *BufferPool) Max() int {
+	return p.max
+}
+
+// BufferStats counts what a BufferPool has done.
+type BufferStats struct {
+	Stats            // summed over the size classes, plus oversized Gets and dropped Puts
+	Oversized uint64 // Gets above the cap, which always make a new buffer
+	Dropped   uint64 // Puts of buffers the pool did not retain
+}
+
+// Stats returns a snapshot of the pool's counters, all zero unless the pool
+// was made WithStats.
+func (p *BufferPool) Stats() BufferStats {
+	var s BufferStats
+	for _, c := range p.classes {
+		cs := c.Stats()
+		s.Gets += cs.Gets
+		s.News += cs.News
+		s.Puts += cs.Puts
+	}
+	if p.stats != nil {
+		s.Oversized = p.stats.oversized.Load()
+		s.Dropped = p.stats.dropped.Load()
+		s.Gets += s.Oversized
+		s.News += s.Oversized
+		s.Puts += s.Dropped
+	}
+	return s
+}
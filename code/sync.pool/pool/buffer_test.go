@@ -0,0 +1,54 @@
+package pool
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_BufferPool(t *testing.T) {
+	p := NewBufferPool(5000, WithStats())
+	if p.Max() != 8192 {
+		t.Errorf("expected the cap rounded up to 8192 but got %d", p.Max())
+	}
+
+	for _, hint := range []int{-1, 0, 1, 64, 65, 1000, 4096, 8192} {
+		b := p.Get(hint)
+		if b.Len() != 0 || b.Cap() < hint {
+			t.Errorf("Get(%d): got len %d cap %d", hint, b.Len(), b.Cap())
+		}
+		b.WriteString("dirty")
+		p.Put(b)
+	}
+
+	big := p.Get(10000)
+	if big.Cap() < 10000 {
+		t.Errorf("Get(10000): got cap %d", big.Cap())
+	}
+	p.Put(big)
+	grown := p.Get(100)
+	grown.Write(make([]byte, 20000))
+	p.Put(grown)
+	p.Put(&bytes.Buffer{}) // below the smallest class
+
+	s := p.Stats()
+	if s.Gets != 10 || s.Puts != 11 || s.Oversized != 1 || s.Dropped != 3 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+// Test_BufferPool_Classes checks that a buffer is filed under a class it
+// can serve: a Get never returns a buffer smaller than its hint, whatever
+// capacities were Put before.
+func Test_BufferPool_Classes(t *testing.T) {
+	p := NewBufferPool(1 << 16)
+	for c := 64; c <= 1<<16; c = c*3/2 + 1 {
+		p.Put(bytes.NewBuffer(make([]byte, 0, c)))
+	}
+	for hint := 1; hint <= 1<<16; hint = hint*5/4 + 1 {
+		b := p.Get(hint)
+		if b.Cap() < hint {
+			t.Errorf("Get(%d): got cap %d", hint, b.Cap())
+		}
+		p.Put(b)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !pooldebug
+
+package pool
+
+// debugEnabled is false in normal builds, so the compiler drops every
+// debug branch and debugState takes no space.
+const debugEnabled = false
+
+type debugState[T any] struct{}
+
+func (*debugState[T]) init(poison any)                  {}
+func (*debugState[T]) get(reset func(T)) (x T, ok bool) { return x, false }
+func (*debugState[T]) put(x T, reset func(T))           {}
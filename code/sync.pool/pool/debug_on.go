@@ -0,0 +1,80 @@
+//go:build pooldebug
+
+package pool
+
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+)
+
+// In builds with -tags pooldebug, as in
+//
+//	go test -tags pooldebug ./...
+//
+// a Pool keeps its free values in a list of its own instead of the
+// sync.Pool, so no GC can drop them and every misuse is caught the same way
+// on every run:
+//   - Put of a value that is already in the pool panics, with the stacks of
+//     both Puts. This needs a comparable T, such as a pointer.
+//   - Put resets the value and then poisons it (see WithPoison), so code
+//     that keeps using it after Put reads garbage.
+//   - Get resets the value again before handing it out.
+const debugEnabled = true
+
+type debugState[T any] struct {
+	mu         sync.Mutex
+	free       []T
+	pooled     map[any][]byte // the values in free, with the stack of their Put
+	comparable bool
+	poison     func(T)
+}
+
+func (d *debugState[T]) init(poison any) {
+	d.pooled = make(map[any][]byte)
+	d.comparable = reflect.TypeFor[T]().Comparable()
+	if poison != nil {
+		f, ok := poison.(func(T))
+		if !ok {
+			panic(fmt.Sprintf("pool: WithPoison got a %T for a pool of %v", poison, reflect.TypeFor[T]()))
+		}
+		d.poison = f
+	}
+}
+
+func (d *debugState[T]) get(reset func(T)) (x T, ok bool) {
+	d.mu.Lock()
+	if len(d.free) == 0 {
+		d.mu.Unlock()
+		return x, false
+	}
+	var zero T
+	x, d.free[len(d.free)-1] = d.free[len(d.free)-1], zero
+	d.free = d.free[:len(d.free)-1]
+	if d.comparable {
+		delete(d.pooled, any(x))
+	}
+	d.mu.Unlock()
+
+	reset(x) // wipe the poison
+	return x, true
+}
+
+func (d *debugState[T]) put(x T, reset func(T)) {
+	stack := debug.Stack()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.comparable {
+		if first, ok := d.pooled[any(x)]; ok {
+			panic(fmt.Sprintf("pool: %T put twice without a Get in between\n\nfirst Put:\n%s\nsecond Put:\n%s", x, first, stack))
+		}
+		d.pooled[any(x)] = stack
+	}
+	reset(x)
+	if d.poison != nil {
+		d.poison(x)
+	}
+	d.free = append(d.free, x)
+}
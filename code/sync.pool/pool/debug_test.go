@@ -0,0 +1,73 @@
+//go:build pooldebug
+
+package pool
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func Test_Debug_DoublePut(t *testing.T) {
+	p := New(func() *bytes.Buffer { return &bytes.Buffer{} }, (*bytes.Buffer).Reset)
+	b := p.Get()
+	firstPut(p, b)
+
+	defer func() {
+		msg := fmt.Sprint(recover())
+		if !strings.Contains(msg, "put twice") {
+			t.Fatalf("expected a double Put panic but got %q", msg)
+		}
+		// both stacks, each naming the function that did the Put
+		if !strings.Contains(msg, "first Put:") || !strings.Contains(msg, "pool.firstPut") ||
+			!strings.Contains(msg, "second Put:") || !strings.Contains(msg, "Test_Debug_DoublePut") {
+			t.Errorf("expected both stacks in the panic but got\n%s", msg)
+		}
+	}()
+	p.Put(b)
+}
+
+func firstPut(p *Pool[*bytes.Buffer], b *bytes.Buffer) {
+	p.Put(b)
+}
+
+func Test_Debug_GetAfterPut(t *testing.T) {
+	p := New(func() *int { return new(int) }, func(x *int) { *x = 0 },
+		WithPoison(func(x *int) { *x = -1 }))
+
+	x := p.Get()
+	*x = 42
+	p.Put(x)
+	if *x != -1 {
+		t.Errorf("expected the value poisoned after Put but got %d", *x)
+	}
+	if y := p.Get(); y != x || *y != 0 {
+		t.Errorf("expected the same value back, reset, but got %p = %d", y, *y)
+	}
+	p.Put(x) // back in the pool once, no panic
+}
+
+func Test_Debug_PoisonBuffer(t *testing.T) {
+	p := NewBufferPool(1 << 10)
+	b := p.Get(100)
+	b.WriteString("secret")
+	kept := b.Bytes() // a slice that outlives the Put
+	p.Put(b)
+
+	if string(kept) != "\xde\xad\xbe\xef\xde\xad" {
+		t.Errorf("expected a use after Put to read the poison but got %q", kept)
+	}
+	if b := p.Get(100); b.Len() != 0 {
+		t.Errorf("expected an empty buffer but got %q", b.Bytes())
+	}
+}
+
+func Test_Debug_WrongPoison(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected New to panic on a poison for another type")
+		}
+	}()
+	New(func() *int { return new(int) }, func(x *int) {}, WithPoison(func(*string) {}))
+}
@@ -0,0 +1,77 @@
+package pool
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func Test_Pool(t *testing.T) {
+	p := New(func() *bytes.Buffer { return &bytes.Buffer{} }, (*bytes.Buffer).Reset, WithStats())
+
+	b := p.Get()
+	b.WriteString("dirty")
+	p.Put(b)
+	if b.Len() != 0 {
+		t.Errorf("expected Put to reset the buffer but it holds %q", b.String())
+	}
+
+	// sync.Pool may drop any Put, and under the race detector it drops
+	// some on purpose, so the second Get need not be a hit.
+	p.Put(p.Get())
+	s := p.Stats()
+	if s.Gets != 2 || s.Puts != 2 || s.News < 1 || s.News > 2 {
+		t.Errorf("unexpected stats %v", s)
+	}
+	if s.Hits()+s.News != s.Gets {
+		t.Errorf("hits %d + news %d != gets %d", s.Hits(), s.News, s.Gets)
+	}
+	if d := p.Stats().Sub(s); d != (Stats{}) {
+		t.Errorf("expected no change since the last snapshot but got %v", d)
+	}
+}
+
+func Test_Pool_Concurrent(t *testing.T) {
+	p := New(func() *[]int { return new([]int) }, func(s *[]int) { *s = (*s)[:0] }, WithStats())
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				s := p.Get()
+				if len(*s) != 0 {
+					t.Errorf("got a dirty value %v", *s)
+				}
+				*s = append(*s, i)
+				p.Put(s)
+				if st := p.Stats(); st.News > st.Gets {
+					t.Errorf("more news than gets: %v", st)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if s := p.Stats(); s.Gets != 8000 || s.Puts != 8000 {
+		t.Errorf("unexpected stats %v", s)
+	}
+}
+
+func Test_Pool_NoStats(t *testing.T) {
+	p := New(func() *int { return new(int) }, func(x *int) { *x = 0 })
+	p.Put(p.Get())
+	if s := p.Stats(); s != (Stats{}) {
+		t.Errorf("expected zero stats without WithStats but got %v", s)
+	}
+}
+
+func Test_New_NeedsReset(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected New to panic without a reset function")
+		}
+	}()
+	New(func() *int { return new(int) }, nil)
+}
@@ -1,30 +1,46 @@
+// Each benchmark sorts fresh copies of generated data, per size and
+// distribution (see mergesort/sorttest):
+//
+// go test -run none -bench 'v3/n=1000000/'
 package main
 
-import "testing"
+import (
+	"slices"
+	"testing"
+
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort"
+	"github.com/sathishvj/optimizing-go-programs/code/mergesort/sorttest"
+)
 
 func Benchmark_mergesortv1(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		mergesortv1(s)
-	}
+	sorttest.Benchmark(b, func(s []int) {
+		mergesort.Sort(s, mergesort.WithStrategy(mergesort.V1))
+	})
 }
 
 func Benchmark_mergesortv2(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		mergesortv2(s)
-	}
+	sorttest.Benchmark(b, func(s []int) {
+		mergesort.Sort(s, mergesort.WithStrategy(mergesort.V2))
+	})
 }
 
 func Benchmark_mergesortv3(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		mergesortv3(s)
-	}
+	sorttest.Benchmark(b, func(s []int) {
+		mergesort.Sort(s, mergesort.WithStrategy(mergesort.V3))
+	})
+}
+
+func Benchmark_mergesortv4(b *testing.B) {
+	sorttest.Benchmark(b, func(s []int) {
+		mergesort.Sort(s, mergesort.WithStrategy(mergesort.V4))
+	})
 }
 
 func Test_mergesortv1(t *testing.T) {
 	inp := []int{89, 123, 12, 9, 198, 1546, 108, 872, 93}
 	exp := []int{9, 12, 89, 93, 108, 123, 198, 872, 1546}
-	mergesortv1(inp)
-	if inp[0] != exp[0] && inp[len(exp)-1] != exp[len(exp)-1] {
-		t.Errorf("Test failed")
+	mergesort.Sort(inp, mergesort.WithStrategy(mergesort.V1))
+	if !slices.Equal(inp, exp) {
+		t.Errorf("expected %v but got %v", exp, inp)
 	}
 }